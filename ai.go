@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"strings"
 )
 
 type AI interface {
@@ -23,13 +24,43 @@ var ais = map[string]func(*Game, string) AI{
 	"rollingtreeai": NewRollingTreeAI,
 	"mcai":          NewMonteCarloid,
 	"cmc":           NewCMonteCarloid,
+	"beamai":        NewBeamAI,
+	"mcts":          NewMCTSAI,
+	"annealai":      NewAnnealAI,
+	"gaai":          NewGeneticAI,
 }
 
+// NewAI returns the AI called aiType. An evaluator for search AIs to use
+// may follow the name after a colon, like "treeai:weighted", which
+// overrides -eval.
 func NewAI(g *Game, aiType string, repeatStr string) AI {
+	evalName := *evalFlag
+	if i := strings.Index(aiType, ":"); i >= 0 {
+		aiType, evalName = aiType[:i], aiType[i+1:]
+	}
+
 	fn, ok := ais[aiType]
 	if !ok {
 		log.Printf("Invalid AI %q", aiType)
 	}
 
-	return fn(g, repeatStr)
+	ai := fn(g, repeatStr)
+	if evalName != "" {
+		e, err := NewEvaluator(evalName)
+		if err != nil {
+			log.Printf("AI %q: %v", aiType, err)
+		} else if s, ok := ai.(EvaluatorSetter); ok {
+			s.SetEvaluator(e)
+		} else {
+			log.Printf("AI %q does not take an evaluator", aiType)
+		}
+	}
+
+	if *endgameUnits > 0 {
+		ai = NewEndgameAI(ai, *endgameUnits)
+	}
+	if *burn {
+		ai = NewBurnAI(ai)
+	}
+	return ai
 }
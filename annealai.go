@@ -0,0 +1,143 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	// annealStartTemp and annealEndTemp are the temperatures, in points,
+	// at the start and end of annealing. It cools geometrically between.
+	annealStartTemp = 200.0
+	annealEndTemp   = 1.0
+
+	// annealMaxRank is the worst rank a mutation chooses.
+	annealMaxRank = 4
+	// annealMaxSpan is the most choices a mutation changes.
+	annealMaxSpan = 3
+)
+
+// mutatePlan returns a copy of plan with up to annealMaxSpan choices
+// changed, starting at a random unit among n, and the unit it starts at.
+// Later choices are dropped, so that the rest of the game is greedy.
+func mutatePlan(plan []int, n int) ([]int, int) {
+	k := rand.Intn(n)
+	span := 1 + rand.Intn(annealMaxSpan)
+
+	next := make([]int, k+span)
+	copy(next, plan)
+	for i := k; i < k+span; i++ {
+		next[i] = rand.Intn(annealMaxRank + 1)
+	}
+	return next, k
+}
+
+// anneal improves the plan for g, ranking placements with eval, by
+// simulated annealing until the budget runs out, returning the best plan
+// found, and the greedy plan it started from.
+func anneal(g *Game, eval Evaluator, budget time.Duration) (best, greedy *planResult) {
+	start := time.Now()
+	end := start.Add(budget)
+	if !deadline.IsZero() && deadline.Before(end) {
+		end = deadline
+	}
+
+	greedy = replayPlan(newPlan(g, eval), 0, nil)
+	cur := greedy
+	best = cur
+	log.Printf("Greedy plan scores %f over %d units", cur.Score(), len(cur.placed))
+	if len(cur.placed) == 0 {
+		// There is nothing to mutate.
+		return greedy, greedy
+	}
+
+	iterations := 0
+	for now := start; now.Before(end); now = time.Now() {
+		iterations++
+
+		frac := float64(now.Sub(start)) / float64(end.Sub(start))
+		temp := annealStartTemp * math.Pow(annealEndTemp/annealStartTemp, frac)
+
+		plan, k := mutatePlan(cur.plan, len(cur.placed))
+		next := replayPlan(cur, k, plan)
+
+		delta := next.Score() - cur.Score()
+		if delta >= 0 || rand.Float64() < math.Exp(delta/temp) {
+			cur = next
+		}
+
+		if cur.Score() > best.Score() {
+			best = cur
+			log.Printf("Annealing iteration %d temp %f: best %f", iterations, temp, best.Score())
+		}
+	}
+
+	log.Printf("Annealed %d iterations, best %f", iterations, best.Score())
+	return best, greedy
+}
+
+// PlanAI solves the whole game offline, then plays the plan found.
+type PlanAI struct {
+	game     *Game
+	current  Commands
+	searched bool
+
+	eval Evaluator
+	// solve returns the plan to play, and the greedy plan, to explain
+	// why.
+	solve func(g *Game, eval Evaluator) (best, greedy *planResult)
+	explained
+}
+
+func NewAnnealAI(g *Game, _ string) AI {
+	return &PlanAI{
+		game: g,
+		eval: &WeightedEvaluator{Weights: defaultWeights},
+		solve: func(g *Game, eval Evaluator) (*planResult, *planResult) {
+			return anneal(g, eval, *annealTime)
+		},
+	}
+}
+
+// Game returns the Game used by the AI.
+// It may change after calls to Next().
+func (a *PlanAI) Game() *Game {
+	return a.game
+}
+
+// SetEvaluator implements EvaluatorSetter.
+func (a *PlanAI) SetEvaluator(e Evaluator) {
+	a.eval = e
+}
+
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (a *PlanAI) Next() (bool, error) {
+	if !a.searched {
+		a.searched = true
+		r, greedy := a.solve(a.game, a.eval)
+
+		// The search ranked plans without chanting, which greedy may
+		// still win once phrases are counted.
+		best := chantPlan("searched", r, a.game)
+		other := chantPlan("greedy", greedy, a.game)
+		if other.game.Objective() > best.game.Objective() {
+			best, other = other, best
+		}
+		a.last = explainPlan(best, other)
+		a.current = append(Commands(nil), best.game.Commands[len(a.game.Commands):]...)
+	}
+
+	if len(a.current) == 0 {
+		return true, nil
+	}
+
+	c := a.current[0]
+	a.current = a.current[1:]
+
+	locked, done, err := a.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	return done, err
+}
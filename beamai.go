@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+var (
+	// beamWidth is the number of games kept after each unit.
+	beamWidth = 8
+	// beamDepth is the number of units searched ahead.
+	beamDepth = 2
+
+	// deathPenalty ranks games which end early below everything else.
+	deathPenalty = 1000000.0
+)
+
+type beamState struct {
+	g     *Game
+	first *Placement
+	value float64
+	done  bool
+}
+
+type byBeamValue []beamState
+
+func (s byBeamValue) Len() int           { return len(s) }
+func (s byBeamValue) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byBeamValue) Less(i, j int) bool { return s[i].value > s[j].value }
+
+// BeamAI searches over whole placements. For each unit it keeps the best
+// beamWidth games, and the best which holds back a clear for the next unit,
+// expands each by every reachable placement, and continues for beamDepth
+// units. It then plays the first placement of the best line, chanting
+// phrases on the way.
+type BeamAI struct {
+	game    *Game
+	current Commands
+	eval    Evaluator
+	explained
+}
+
+func NewBeamAI(g *Game, _ string) AI {
+	return &BeamAI{game: g, eval: &WeightedEvaluator{Weights: defaultWeights}}
+}
+
+// Game returns the Game used by the AI.
+// It may change after calls to Next().
+func (a *BeamAI) Game() *Game {
+	return a.game
+}
+
+// SetEvaluator implements EvaluatorSetter.
+func (a *BeamAI) SetEvaluator(e Evaluator) {
+	a.eval = e
+}
+
+// expand returns the games after every placement from s, except those
+// which cannot be best.
+func (s beamState) expand(eval Evaluator) []beamState {
+	var next []beamState
+	for _, e := range s.g.Expand(eval) {
+		first := s.first
+		if first == nil {
+			p := e.Placement
+			first = &p
+		}
+
+		next = append(next, beamState{
+			g:     e.Game,
+			first: first,
+			value: e.Value,
+			done:  e.Done,
+		})
+	}
+	return next
+}
+
+// holdBack returns beam, with the best state of all which cleared no lines
+// added, if the beam only keeps states which clear lines, and the next unit
+// could clear more lines at once from there. Clearing lines together scores
+// more than clearing them one at a time, but holding back a clear scores
+// nothing yet, so the beam would not keep it. All must be sorted, best
+// first.
+func holdBack(all, beam []beamState) []beamState {
+	cleared := 0
+	for _, s := range beam {
+		if s.done {
+			continue
+		}
+		if s.g.previousLinesCleared == 0 {
+			return beam
+		}
+		if s.g.previousLinesCleared > cleared {
+			cleared = s.g.previousLinesCleared
+		}
+	}
+	if cleared == 0 {
+		return beam
+	}
+
+	for _, s := range all {
+		if s.done || s.g.previousLinesCleared > 0 {
+			continue
+		}
+
+		if ts := s.g.Upcoming(1); len(ts) > 0 && s.g.maxClear(ts[0]) > cleared {
+			return append(beam[:len(beam):len(beam)], s)
+		}
+		return beam
+	}
+	return beam
+}
+
+// plan returns the first placement of the best line found.
+func (a *BeamAI) plan() *Placement {
+	beam := []beamState{{g: a.game}}
+
+	for depth := 0; depth < beamDepth; depth++ {
+		var next []beamState
+		live := false
+		for _, s := range beam {
+			if s.done {
+				next = append(next, s)
+				continue
+			}
+			live = true
+			next = append(next, s.expand(a.eval)...)
+		}
+
+		if !live || len(next) == 0 {
+			break
+		}
+
+		sort.Stable(byBeamValue(next))
+		beam = next
+		if len(beam) > beamWidth {
+			beam = holdBack(next, next[:beamWidth])
+		}
+	}
+
+	a.last = a.explain(beam)
+	return beam[0].first
+}
+
+// explain explains choosing the first placement on the path to the best
+// game in beam, over the best game reached from another.
+func (a *BeamAI) explain(beam []beamState) *Explanation {
+	choice := func(s beamState) Choice {
+		c := Choice{Score: s.value, Terms: searchTerms(a.eval, s.g, nil, s.done)}
+		if s.first != nil {
+			c.Move = fmt.Sprintf("%+v", s.first.Position)
+		}
+		return c
+	}
+
+	e := &Explanation{Chosen: choice(beam[0])}
+	for _, s := range beam[1:] {
+		if s.first != nil && beam[0].first != nil && a.game.Key(s.first.Position) != a.game.Key(beam[0].first.Position) {
+			c := choice(s)
+			e.RunnerUp = &c
+			break
+		}
+	}
+	return e
+}
+
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (a *BeamAI) Next() (bool, error) {
+	if len(a.current) == 0 {
+		if p := a.plan(); p != nil {
+			a.current = a.game.PhrasePath(*p)
+			log.Printf("Placing at %+v with %s", p.Position, &a.current)
+		} else {
+			a.current = Commands{directionToCommands[SE][0]}
+		}
+	}
+
+	c := a.current[0]
+	a.current = a.current[1:]
+
+	locked, done, err := a.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	if locked {
+		a.current = nil
+	}
+	return done, err
+}
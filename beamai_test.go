@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHoldBack(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// The pair fills 1 and 2 of row 3 to clear it now. Holding it back on
+	// 0 and 1 of row 2 lets the upright pair after it fill 2 of row 2 and 1
+	// of row 3, clearing both at once.
+	//
+	//    . . .
+	//     . . .
+	//    . . .
+	//     x . x
+	//    . x x
+	p := &InputProblem{
+		Units: []Unit{
+			Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}},
+			Unit{Members: []Cell{{0, 0}, {0, 1}}, Pivot: Cell{0, 0}},
+		},
+		Width:        3,
+		Height:       5,
+		Filled:       []Cell{{0, 3}, {2, 3}, {1, 4}, {2, 4}},
+		SourceLength: 2,
+		SourceSeeds:  []uint64{37},
+	}
+	g := GamesFromProblem(p)[0]
+	if got := g.Upcoming(2); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Fatalf("Upcoming(2) got %v, want [0 1]", got)
+	}
+
+	// Only the best game is kept, which is a clear.
+	defer func(w int) { beamWidth = w }(beamWidth)
+	beamWidth = 1
+
+	a := NewBeamAI(g, "").(*BeamAI)
+	best := g.Expand(a.eval)[0]
+	for _, e := range g.Expand(a.eval) {
+		if e.Value > best.Value {
+			best = e
+		}
+	}
+	if best.Game.previousLinesCleared != 1 {
+		t.Fatalf("best placement %+v cleared %d lines, want 1", best.Position, best.Game.previousLinesCleared)
+	}
+
+	play := func(pl *Placement) *Game {
+		f := g.Fork()
+		f.Play(pl.Commands)
+		if next := NewBeamAI(f, "").(*BeamAI).plan(); next != nil {
+			f.Play(next.Commands)
+		}
+		return f
+	}
+
+	pl := a.plan()
+	if pl == nil {
+		t.Fatalf("no placement planned")
+	}
+	f := g.Fork()
+	f.Play(pl.Commands)
+	if f.previousLinesCleared != 0 {
+		t.Errorf("planned %+v, which clears a line now", pl.Position)
+	}
+	if got := f.maxClear(f.currUnit.Template); got != 2 {
+		t.Errorf("the next unit can clear %d lines after %+v, want 2", got, pl.Position)
+	}
+
+	held, cleared := play(pl), play(&best.Placement)
+	if held.moveScore <= cleared.moveScore {
+		t.Errorf("holding back scored %v, clearing now %v", held.moveScore, cleared.moveScore)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -17,6 +18,15 @@ type Board struct {
 	Width  int
 	Height int
 	Cells  [][]BoardCell
+
+	// changes logs each cell whose filled state flipped, in order, once
+	// Track is called, so that each watcher can find what changed since it
+	// last looked.
+	changes []Cell
+	tracked bool
+
+	// Zobrist hash of the filled cells.
+	hash uint64
 }
 
 func NewBoard(w, h int, filled []Cell) *Board {
@@ -49,7 +59,7 @@ func NewBoard(w, h int, filled []Cell) *Board {
 func (b *Board) Fork() *Board {
 	w := b.Width
 	h := b.Height
-	bcopy := &Board{Width: w, Height: h}
+	bcopy := &Board{Width: w, Height: h, hash: b.hash}
 
 	// Make columns, according to [w][h]Cell.
 	bcopy.Cells = make([][]BoardCell, w)
@@ -66,9 +76,113 @@ func (b *Board) Fork() *Board {
 		}
 	}
 
+	// Forks start untracked, so searches pay nothing for tracking.
 	return bcopy
 }
 
+// Track starts logging changes to the board, if it is not already.
+// Untracked boards (the default) pay nothing for tracking.
+func (b *Board) Track() {
+	b.tracked = true
+}
+
+// Changes returns the cells whose filled state differs from when the log
+// was mark long, sorted by row and then column, and the length of the log
+// now, to pass next time.
+func (b *Board) Changes(mark int) ([]BoardCell, int) {
+	// Every entry is a flip, so a cell flipped an even number of times is
+	// as it was.
+	flips := make(map[Cell]int)
+	for _, c := range b.changes[mark:] {
+		flips[c]++
+	}
+
+	var deltas []BoardCell
+	for c, n := range flips {
+		if n%2 == 1 {
+			deltas = append(deltas, *b.BoardCell(c))
+		}
+	}
+
+	sort.Sort(byRow(deltas))
+	return deltas, len(b.changes)
+}
+
+// diff returns the cells of b whose filled state differs from o, sorted by
+// row and then column.
+func (b *Board) diff(o *Board) []BoardCell {
+	var deltas []BoardCell
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if bc := b.Cells[x][y]; bc.Filled != o.Cells[x][y].Filled {
+				deltas = append(deltas, bc)
+			}
+		}
+	}
+	return deltas
+}
+
+// boardWatcher follows the board of a game as it is played, to find what
+// changed from one frame to the next without comparing every cell. AIs
+// which swap in a forked game leave a board with no log of the changes,
+// so then the boards are compared in full.
+type boardWatcher struct {
+	b    *Board
+	mark int
+}
+
+// Changes returns the cells changed between the board last watched and b,
+// and starts watching b. The first call returns nil.
+func (w *boardWatcher) Changes(b *Board) []BoardCell {
+	var deltas []BoardCell
+	switch {
+	case w.b == nil:
+	case w.b == b:
+		deltas, w.mark = b.Changes(w.mark)
+	default:
+		// What was seen of the old board is its state at the mark.
+		seen := w.b.Fork()
+		moved, _ := w.b.Changes(w.mark)
+		for _, bc := range moved {
+			seen.setFilled(bc.Cell, !bc.Filled)
+		}
+		deltas = b.diff(seen)
+	}
+
+	if w.b != b {
+		b.Track()
+		w.b, w.mark = b, len(b.changes)
+	}
+	return deltas
+}
+
+type byRow []BoardCell
+
+func (s byRow) Len() int      { return len(s) }
+func (s byRow) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byRow) Less(i, j int) bool {
+	if s[i].Y != s[j].Y {
+		return s[i].Y < s[j].Y
+	}
+	return s[i].X < s[j].X
+}
+
+// setFilled is the only place cell state is modified, so that changes can be
+// tracked.
+func (b *Board) setFilled(c Cell, filled bool) {
+	bc := b.BoardCell(c)
+	if bc.Filled == filled {
+		return
+	}
+
+	if b.tracked {
+		b.changes = append(b.changes, c)
+	}
+
+	bc.Filled = filled
+	b.hash ^= cellHash(c)
+}
+
 // Pretty-print Board, indenting n levels
 func (b *Board) StringLevel(n int) string {
 	indent := strings.Repeat("\t", n)
@@ -90,11 +204,11 @@ func (b *Board) BoardCell(c Cell) *BoardCell {
 }
 
 func (b *Board) MarkFilled(c Cell) {
-	b.BoardCell(c).Filled = true
+	b.setFilled(c, true)
 }
 
 func (b *Board) MarkUnfilled(c Cell) {
-	b.BoardCell(c).Filled = false
+	b.setFilled(c, false)
 }
 
 func (b *Board) IsFilled(c Cell) bool {
@@ -119,7 +233,7 @@ func (b *Board) RowIsFilled(row int) bool {
 
 func (b *Board) UnfillRow(row int) bool {
 	for i := 0; i < b.Width; i++ {
-		b.Cells[i][row].Filled = false
+		b.MarkUnfilled(Cell{X: i, Y: row})
 	}
 
 	return true
@@ -190,8 +304,8 @@ func (b *Board) GapBelow(c Cell, layers int) bool {
 	return swEmpty || seEmpty || b.GapBelow(sw, layers-1) || b.GapBelow(se, layers-1)
 }
 
-func (b *Board) GapBelowAny(u *Unit) bool {
-	for _, c := range u.Members {
+func (b *Board) GapBelowAny(cells []Cell) bool {
+	for _, c := range cells {
 		if b.GapBelow(c, 3) {
 			return true
 		}
@@ -199,3 +313,71 @@ func (b *Board) GapBelowAny(u *Unit) bool {
 
 	return false
 }
+
+// CenterUnit moves u into its spawn position: centered at the top of the
+// board, rounding down and leaving less space on the left.
+func (b *Board) CenterUnit(u *Unit) {
+	l, r := u.Bounds()
+
+	uwidth := r.X - l.X + 1
+	extraSpace := b.Width - uwidth
+
+	// Center up, rounding down, leaving less space on the left.
+	rightShift := extraSpace / 2
+	// If the leftmost cell is not at zero, we don't need to shift as much.
+	rightShift -= l.X
+	for i := range u.Members {
+		u.Members[i].X += rightShift
+	}
+	u.Pivot.X += rightShift
+}
+
+// SpawnReport describes how close the board is to blocking new units.
+type SpawnReport struct {
+	// CanSpawn[i] is whether template i could be placed now.
+	CanSpawn []bool
+	// Blocked is the number of templates which could not be placed.
+	Blocked int
+	// SpawnRows is the number of rows covered by spawning units.
+	SpawnRows int
+	// NearSpawn is the number of filled cells within K rows below the
+	// spawn area (or inside it).
+	NearSpawn int
+}
+
+// SpawnBlockage reports which of the templates could still spawn, and how
+// crowded the board is within k rows of the spawn area.
+func (b *Board) SpawnBlockage(templates []Unit, k int) SpawnReport {
+	r := SpawnReport{CanSpawn: make([]bool, len(templates))}
+
+	for i := range templates {
+		u := templates[i].DeepCopy()
+		b.CenterUnit(u)
+
+		for _, c := range u.Members {
+			if c.Y+1 > r.SpawnRows {
+				r.SpawnRows = c.Y + 1
+			}
+		}
+
+		r.CanSpawn[i] = b.IsValid(u)
+		if !r.CanSpawn[i] {
+			r.Blocked++
+		}
+	}
+
+	rows := r.SpawnRows + k
+	if rows > b.Height {
+		rows = b.Height
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < b.Width; x++ {
+			if b.Cells[x][y].Filled {
+				r.NearSpawn++
+			}
+		}
+	}
+
+	return r
+}
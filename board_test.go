@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSpawnBlockage(t *testing.T) {
+	templates := []Unit{
+		// Single cell.
+		Unit{Members: []Cell{{0, 0}}, Pivot: Cell{0, 0}},
+		// Vertical pair, reaching into row 1.
+		Unit{Members: []Cell{{0, 0}, {0, 1}}, Pivot: Cell{0, 0}},
+	}
+
+	b := NewBoard(5, 10, nil)
+	r := b.SpawnBlockage(templates, 2)
+	if r.Blocked != 0 || !r.CanSpawn[0] || !r.CanSpawn[1] {
+		t.Errorf("empty board SpawnBlockage got %+v, want all spawnable", r)
+	}
+	if r.SpawnRows != 2 {
+		t.Errorf("SpawnRows got %d want 2", r.SpawnRows)
+	}
+
+	// Block the second template's lower cell, and fill a cell just
+	// inside and just outside the K rows.
+	b.MarkFilled(Cell{2, 1})
+	b.MarkFilled(Cell{0, 3})
+	b.MarkFilled(Cell{0, 4})
+
+	r = b.SpawnBlockage(templates, 2)
+	if !r.CanSpawn[0] || r.CanSpawn[1] || r.Blocked != 1 {
+		t.Errorf("SpawnBlockage got %+v, want only template 1 blocked", r)
+	}
+	if r.NearSpawn != 2 {
+		t.Errorf("NearSpawn got %d want 2", r.NearSpawn)
+	}
+}
+
+func TestBoardChanges(t *testing.T) {
+	b := NewBoard(4, 4, []Cell{{0, 3}})
+	b.Track()
+
+	b.MarkFilled(Cell{1, 3})
+	b.MarkFilled(Cell{2, 2})
+	got, mark := b.Changes(0)
+	if len(got) != 2 || got[0].Cell != (Cell{2, 2}) || got[1].Cell != (Cell{1, 3}) {
+		t.Errorf("Changes(0) got %v, want (2, 2) then (1, 3)", got)
+	}
+
+	// A cell filled and emptied again is unchanged.
+	b.MarkFilled(Cell{3, 0})
+	b.MarkUnfilled(Cell{3, 0})
+	b.MarkUnfilled(Cell{0, 3})
+	if got, _ := b.Changes(mark); len(got) != 1 || got[0] != (BoardCell{Cell{0, 3}, false}) {
+		t.Errorf("Changes(%d) got %v, want (0, 3) emptied", mark, got)
+	}
+
+	// Searches fork the board, and pay nothing for tracking.
+	f := b.Fork()
+	f.MarkFilled(Cell{0, 0})
+	if f.tracked || len(f.changes) != 0 {
+		t.Errorf("fork tracked %v with %d changes", f.tracked, len(f.changes))
+	}
+}
+
+func TestBoardWatcher(t *testing.T) {
+	b := NewBoard(4, 4, nil)
+
+	var w boardWatcher
+	if got := w.Changes(b); got != nil {
+		t.Errorf("first Changes got %v, want nil", got)
+	}
+
+	b.MarkFilled(Cell{1, 3})
+	if got := w.Changes(b); len(got) != 1 || got[0].Cell != (Cell{1, 3}) {
+		t.Errorf("Changes got %v, want (1, 3)", got)
+	}
+
+	// A fork swapped in has no log, and is compared with what was last
+	// seen, not what the old board became since.
+	b.MarkFilled(Cell{2, 3})
+	f := b.Fork()
+	f.MarkFilled(Cell{3, 3})
+	b.MarkFilled(Cell{0, 0})
+	got := w.Changes(f)
+	if len(got) != 2 || got[0].Cell != (Cell{2, 3}) || got[1].Cell != (Cell{3, 3}) {
+		t.Errorf("Changes after a swap got %v, want (2, 3) and (3, 3)", got)
+	}
+
+	f.MarkUnfilled(Cell{1, 3})
+	if got := w.Changes(f); len(got) != 1 || got[0] != (BoardCell{Cell{1, 3}, false}) {
+		t.Errorf("Changes got %v, want (1, 3) emptied", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"log"
+)
+
+var (
+	// burnMaxSteps caps the length of the walk searched for the last unit.
+	burnMaxSteps = 4000
+
+	// burnBeamWidth is the number of partial walks kept. The last unit is
+	// only searched once, so this can be much wider than chantBeamWidth.
+	burnBeamWidth = 5000
+)
+
+// BurnPath returns commands which walk the last unit over as much of the
+// free board as pays, chanting phrases of power, before locking it. Locking
+// the last unit has no consequences beyond its own move score, so there is
+// no reason to hurry.
+func (g *Game) BurnPath() (Commands, float64, bool) {
+	// Every position may be visited once, so the walk is at most one step
+	// per free cell for each rotation.
+	free := 0
+	for x := 0; x < g.B.Width; x++ {
+		for y := 0; y < g.B.Height; y++ {
+			if !g.B.Cells[x][y].Filled {
+				free++
+			}
+		}
+	}
+
+	steps := free * g.Orientations().Period
+	if steps > burnMaxSteps {
+		steps = burnMaxSteps
+	}
+
+	return g.finishPath(steps, burnBeamWidth)
+}
+
+// BurnAI wraps another AI, and takes over for the last unit of the game to
+// spend it on phrases of power.
+type BurnAI struct {
+	ai AI
+
+	// game is the game being played, once the last unit has spawned.
+	game    *Game
+	current Commands
+	explained
+}
+
+func NewBurnAI(ai AI) AI {
+	return &BurnAI{ai: ai}
+}
+
+// Game returns the Game used by the AI.
+// It may change after calls to Next().
+func (a *BurnAI) Game() *Game {
+	if a.game != nil {
+		return a.game
+	}
+	return a.ai.Game()
+}
+
+// Explain implements Explainer, explaining the wrapped AI's decisions until
+// the burn takes over.
+func (a *BurnAI) Explain() *Explanation {
+	if a.game == nil {
+		return explain(a.ai)
+	}
+	return a.explained.Explain()
+}
+
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (a *BurnAI) Next() (bool, error) {
+	if a.game == nil {
+		g := a.ai.Game()
+		if g.unitsLeft() > 1 || len(g.previousMoves) > 0 {
+			return a.ai.Next()
+		}
+
+		cs, score, ok := g.BurnPath()
+		if !ok {
+			return a.ai.Next()
+		}
+
+		log.Printf("Burning last unit for %f with %s", score, &cs)
+		a.last = &Explanation{Chosen: Choice{
+			Move:  cs.String(),
+			Score: score,
+			Terms: map[string]float64{"burn": score},
+		}}
+		a.game = g
+		a.current = cs
+	}
+
+	if len(a.current) == 0 {
+		// The burn path locked, so the game should be over.
+		a.current = Commands{directionToCommands[SE][0]}
+	}
+
+	c := a.current[0]
+	a.current = a.current[1:]
+
+	locked, done, err := a.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	return done, err
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBurnPath(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// A single unit on a big open board has plenty of room to chant.
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        10,
+		Height:       20,
+		SourceLength: 1,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	_, finish, ok := g.FinishPath()
+	if !ok {
+		t.Fatalf("FinishPath() not ok")
+	}
+
+	cs, burn, ok := g.BurnPath()
+	if !ok {
+		t.Fatalf("BurnPath() not ok")
+	}
+	if burn <= finish {
+		t.Errorf("BurnPath() scored %v, want more than FinishPath() %v", burn, finish)
+	}
+
+	f := g.Fork()
+	for i, c := range cs {
+		locked, done, err := f.Update(c)
+		if err != nil {
+			t.Fatalf("Update(%s) err %v", c, err)
+		}
+		if last := i == len(cs)-1; locked != last || done != last {
+			t.Fatalf("Update(%s) command %d of %d locked %v done %v", c, i, len(cs), locked, done)
+		}
+	}
+
+	if got := f.Score(); got != burn {
+		t.Errorf("BurnPath() scored %v, game scored %v", burn, got)
+	}
+}
@@ -127,6 +127,7 @@ func normalizePhrases() {
 	normalizedPhrases = n
 	normalizedCommands = m
 	cary = len(normalizedPhrases)
+	phrases = newPhraseAutomaton(normalizedCommands)
 }
 
 func (c Command) String() string {
@@ -193,15 +194,18 @@ type CubeCell struct {
 }
 
 // All praise the great and merciful http://www.redblobgames.com/grids/hexagons
+//
+// Rows are "odd-r": odd rows are shoved right by half a cell. The parity of
+// a row is taken with &1 rather than %2 so that negative rows work too.
 func (c Cell) ToCube() CubeCell {
-	q := c.X - int((c.Y-(c.Y%2))/2)
+	q := c.X - (c.Y-(c.Y&1))/2
 	r := c.Y
 	s := -q - r
 	return CubeCell{q, r, s}
 }
 
 func (cc CubeCell) ToCell() Cell {
-	col := cc.X + int((cc.Y-(cc.Y%2))/2)
+	col := cc.X + (cc.Y-(cc.Y&1))/2
 	row := cc.Y
 	return Cell{col, row}
 }
@@ -233,7 +237,7 @@ func (c CubeCell) VectorFrom(other CubeCell) CubeVector {
 func (c CubeCell) Add(v CubeVector) CubeCell {
 	x := c.X + v.X
 	y := c.Y + v.Y
-	z := c.Y + v.Z
+	z := c.Z + v.Z
 	return CubeCell{x, y, z}
 }
 
@@ -15,6 +15,9 @@ type Chant struct {
 	d        Commands
 	children []*Chant
 	game     *Game
+
+	// lock is where the phrase last locked a unit, or nil.
+	lock *UnitPosition
 }
 
 func (n *Chant) BestMove() *Chant {
@@ -59,43 +62,96 @@ func (t *ChanterDescender) Next() (Commands, error) {
 	return next.d, nil
 }
 
-func NewChanterDescender(g *Game) *ChanterDescender {
-	depth := 4
+// NewChanterDescender searches the phrases from g, scoring games with eval,
+// deepening until limit expires, or to chanterDepth if there is no time
+// budget.
+func NewChanterDescender(g *Game, tt *TranspositionTable, limit *searchLimit, eval Evaluator) *ChanterDescender {
 	height := 0
 	root := &Chant{} // fake root
-	root.children = make([]*Chant, cary)
-	for i := range root.children {
-		root.children[i] = BuildScoreChanter(normalizedCommands[i], g, depth-1, height+1)
+	children := make([]*Chant, cary)
+	search := func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64 {
+		children[i] = BuildScoreChanter(normalizedCommands[i], g, depth-1, height+1, tt, limit, eval)
+		return children[i].score
+	}
+	keep := func(searched []bool) {
+		for i, ok := range searched {
+			if !ok {
+				children[i] = prunedChant(normalizedCommands[i])
+			}
+		}
+		root.children = children
+		children = make([]*Chant, cary)
 	}
+	deepen(cary, chanterDepth, limit, tt, search, keep)
 	root.score = root.BestMove().score
 	return &ChanterDescender{root: root}
 }
 
-func BuildScoreChanter(d Commands, g *Game, depth int, height int) *Chant {
+// prunedChant is a dead chant standing in for a phrase which was not
+// searched.
+func prunedChant(d Commands) *Chant {
+	n := &Chant{
+		d:     d,
+		id:    nextId(),
+		score: -1000000000,
+		dead:  true,
+	}
+	return n
+}
+
+// BuildScoreChanter builds the tree of phrases from g, starting with d,
+// scoring each game with eval. If tt is not nil, subtrees already in it are
+// not rebuilt. Once limit expires, the rest of the tree is pruned, so the
+// result is only good if limit has not expired.
+func BuildScoreChanter(d Commands, g *Game, depth int, height int, tt *TranspositionTable, limit *searchLimit, eval Evaluator) *Chant {
+	if limit.Expired() {
+		return prunedChant(d)
+	}
+
 	n := &Chant{
 		d:  d,
-		id: uniqueId,
+		id: nextId(),
 	}
-	uniqueId++
 	n.game = g.Fork()
 	// XXX spinner update
+	var lock *UnitPosition
 	for _, c := range d {
-		_, done, err := n.game.Update(c)
+		unit := n.game.currUnit
+		locked, done, err := n.game.Update(c)
 		if err != nil || done {
 			n.score = -1000000000
 			n.dead = true
 			return n
 		}
+		if locked {
+			lock = &unit
+		}
 	}
-	n.score = n.game.Score()
+	n.lock = lock
+	n.score = eval.Evaluate(n.game, lock)
 	if depth == 0 {
 		return n
 	}
+	var h uint64
+	if tt != nil {
+		h = n.game.Hash()
+	}
+	// Scores add up along the path, so only exact depths are comparable.
+	if e, ok := tt.Lookup(h); ok && e.Depth == depth {
+		n.score += e.Score
+		return n
+	}
 	n.children = make([]*Chant, cary)
 	for i := range n.children {
-		n.children[i] = BuildScoreChanter(normalizedCommands[i], n.game, depth-1, height+1)
+		n.children[i] = BuildScoreChanter(normalizedCommands[i], n.game, depth-1, height+1, tt, limit, eval)
+	}
+	best := n.BestMove()
+	for i, c := range n.children {
+		if c == best && !limit.Expired() {
+			tt.Store(TTEntry{Hash: h, Score: best.score, Depth: depth, Move: i})
+		}
 	}
-	n.score += n.BestMove().score
+	n.score += best.score
 	return n
 }
 
@@ -103,10 +159,13 @@ type ChanterAI struct {
 	index   int
 	game    *Game
 	current Commands
+	tt      *TranspositionTable
+	eval    Evaluator
+	explained
 }
 
 func NewChanterAI(g *Game, _ string) AI {
-	return &ChanterAI{index: 0, game: g}
+	return &ChanterAI{index: 0, game: g, tt: NewTranspositionTable(ttSize), eval: ScoreEvaluator{}}
 }
 
 // Game returns the Game used by the AI.
@@ -115,11 +174,19 @@ func (ai *ChanterAI) Game() *Game {
 	return ai.game
 }
 
+// SetEvaluator implements EvaluatorSetter.
+func (ai *ChanterAI) SetEvaluator(e Evaluator) {
+	ai.eval = e
+}
+
 // Next steps the AI one step, returning true if the game is
 // complete, or an error if the game cannot continue.
 func (ai *ChanterAI) Next() (bool, error) {
 	if ai.current == nil {
-		t := NewChanterDescender(ai.game)
+		t := NewChanterDescender(ai.game, ai.tt, newMoveLimit(), ai.eval)
+		if !t.root.IsLeaf() {
+			ai.last = explainChant(t.root, ai.eval)
+		}
 		current, err := t.Next()
 		if err == errNoMoves {
 			return false, err // no possible moves, we are stuck!
@@ -142,3 +209,41 @@ func (ai *ChanterAI) Next() (bool, error) {
 	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
 	return done, err
 }
+
+// explainChant explains choosing the best phrase from root over the next
+// best live one, as scored with eval.
+func explainChant(root *Chant, eval Evaluator) *Explanation {
+	best := root.BestMove()
+	e := &Explanation{Chosen: chantChoice(best, eval)}
+
+	var next *Chant
+	for _, c := range root.children {
+		if c != best && !c.IsDead() && (next == nil || c.score > next.score) {
+			next = c
+		}
+	}
+	if next != nil {
+		c := chantChoice(next, eval)
+		e.RunnerUp = &c
+	}
+	return e
+}
+
+// chantChoice breaks down the score of n into eval's terms for its game,
+// and the score of the best phrases after it.
+func chantChoice(n *Chant, eval Evaluator) Choice {
+	c := Choice{Move: n.d.String(), Score: n.score, Terms: make(map[string]float64)}
+	if n.IsDead() {
+		return c
+	}
+
+	own := 0.0
+	for k, v := range evalTerms(eval, n.game, n.lock) {
+		c.Terms["eval."+k] = v
+		own += v
+	}
+	if n.score != own {
+		c.Terms["bestMove"] = n.score - own
+	}
+	return c
+}
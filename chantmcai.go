@@ -7,19 +7,19 @@ import (
 func tryDirection(g *Game, cc string, c string, scoresofar float64, tries int) (bool, float64, error) {
 	//defer log.Printf("leaving! %+v\n", n)
 	//log.Printf("tries: %d try dir: %+v node %+v\n", tries, d, n)
-	thisUnit := g.currUnit.DeepCopy()
+	thisUnit := g.currUnit
 	locked, done, err := g.Update(Command(c[0]))
 	if err != nil {
-		return true, scoresofar + g.Score() - 1000000.0, err
+		return true, scoresofar + g.Objective() - 1000000.0, err
 	}
 
 	if done {
-		return true, scoresofar + g.Score() - 1000000.0, nil
+		return true, scoresofar + g.Objective() - 1000000.0, nil
 	}
 
 	if locked {
-		if g.B.GapBelowAny(thisUnit) {
-			return false, scoresofar + g.Score(), nil
+		if g.B.GapBelowAny(g.Cells(thisUnit)) {
+			return false, scoresofar + g.Objective(), nil
 		}
 	}
 
@@ -36,7 +36,7 @@ func tryDirection(g *Game, cc string, c string, scoresofar float64, tries int) (
 	nextcom = defaultPhrases[rn]
 
 	if tries == 0 {
-		return false, scoresofar + g.Score(), err
+		return false, scoresofar + g.Objective(), err
 	}
 
 	for i := 0; i < pathEndRetries; i++ {
@@ -51,6 +51,7 @@ func tryDirection(g *Game, cc string, c string, scoresofar float64, tries int) (
 
 type CMonteCarloid struct {
 	g *Game
+	explained
 }
 
 func NewCMonteCarloid(g *Game, _ string) AI {
@@ -73,20 +74,43 @@ var (
 	cnary = len(dirs)
 )
 
+// rolloutChoice is command, whose random rollout scored score, which is
+// penalized if it died.
+func rolloutChoice(command string, score float64, ded bool) Choice {
+	c := Choice{Move: command, Score: score, Terms: map[string]float64{"rollout": score}}
+	if ded {
+		c.Terms["rollout"] += 1000000.0
+		c.Terms["death"] = -1000000.0
+	}
+	return c
+}
+
 func (m *CMonteCarloid) Next() (bool, error) {
 	sz := len(defaultPhrases)
 	command := defaultPhrases[rand.Intn(sz)]
 
+	// The best of the commands which died, in case one is chosen
+	// anyway.
+	var rejected *Choice
+	reject := func(command string, score float64) {
+		if rejected == nil || score > rejected.Score {
+			c := rolloutChoice(command, score, true)
+			rejected = &c
+		}
+	}
+
 	var ded bool
 	var err error
+	var score float64
 	var found bool
 	for i := 0; i < chantRetries; i++ {
-		ded, _, err = tryDirection(m.g.Fork(), command, command, m.g.Score(), chantDepth)
+		ded, score, err = tryDirection(m.g.Fork(), command, command, m.g.Objective(), chantDepth)
 
 		if !ded {
 			found = true
 			break
 		}
+		reject(command, score)
 
 		//log.Printf("retry needed cause best node %+v ended game\n", best)
 		command = defaultPhrases[rand.Intn(sz)]
@@ -100,13 +124,16 @@ func (m *CMonteCarloid) Next() (bool, error) {
 				command += string(byte(directionToCommands[d][0]))
 			}
 
-			ded, _, err = tryDirection(m.g.Fork(), command, command, m.g.Score(), chantDepth)
+			ded, score, err = tryDirection(m.g.Fork(), command, command, m.g.Objective(), chantDepth)
 			if !ded {
 				break
 			}
+			reject(command, score)
 		}
 	}
 
+	m.last = &Explanation{Chosen: rolloutChoice(command, score, ded), RunnerUp: rejected}
+
 	for _, c := range command {
 		m.g.Update(Command(c))
 	}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+)
+
+var (
+	errDropBlocked   = errors.New("unit locked before reaching drop column")
+	errDropDone      = errors.New("drop ended the game")
+	errDropNoLanding = errors.New("drop never landed")
+)
+
+// rotationCommands returns the shortest sequence of rotations turning a unit
+// clockwise by rotation sixths of a turn.
+func rotationCommands(rotation int) Commands {
+	rotation = ((rotation % 6) + 6) % 6
+
+	var cs Commands
+	if rotation <= 3 {
+		for i := 0; i < rotation; i++ {
+			cs = append(cs, directionToCommands[CW][0])
+		}
+	} else {
+		for i := rotation; i < 6; i++ {
+			cs = append(cs, directionToCommands[CCW][0])
+		}
+	}
+
+	return cs
+}
+
+// shiftCommands returns the E/W moves bringing a pivot in column from to
+// column to.
+func shiftCommands(from, to int) Commands {
+	var cs Commands
+	for ; from < to; from++ {
+		cs = append(cs, directionToCommands[E][0])
+	}
+	for ; from > to; from-- {
+		cs = append(cs, directionToCommands[W][0])
+	}
+	return cs
+}
+
+// straightDown is the diagonal which keeps a cell in row y in the same
+// column. Taking it repeatedly alternates SE and SW.
+func straightDown(y int) Direction {
+	if y%2 == 0 {
+		return SE
+	}
+	return SW
+}
+
+// dropWith plays setup on a fork of g, then drops the unit straight down
+// until it locks. It returns the pivot of the unit as it locked, and all of
+// the commands played.
+func (g *Game) dropWith(setup Commands) (Cell, Commands, error) {
+	f := g.Fork()
+	cs := append(Commands(nil), setup...)
+
+	for _, c := range setup {
+		locked, _, err := f.Update(c)
+		if err != nil {
+			return Cell{}, nil, err
+		}
+		if locked {
+			return Cell{}, nil, errDropBlocked
+		}
+	}
+
+	// Locking the last unit ends the game, but that is fine.
+	last := f.unitsSent >= f.numUnits
+
+	// Each step down moves to a new row, so this must land eventually.
+	for i := 0; i <= f.B.Height; i++ {
+		landing := f.currUnit.Pivot
+		c := directionToCommands[straightDown(landing.Y)][0]
+		cs = append(cs, c)
+
+		locked, done, err := f.Update(c)
+		if err != nil {
+			return Cell{}, nil, err
+		}
+		if locked {
+			if done && !last {
+				return landing, cs, errDropDone
+			}
+			return landing, cs, nil
+		}
+	}
+
+	return Cell{}, nil, errDropNoLanding
+}
+
+// StraightDrop computes where the current unit lands if it is turned
+// clockwise by rotation sixths of a turn (skipping full symmetric turns),
+// moved so its pivot is in column, and then moved straight down,
+// alternating SW and SE, until it locks.
+//
+// It returns the pivot of the unit where it locked and the commands to get
+// there, including the final command which locks the unit. An error is
+// returned if the unit cannot reach the column, or the lock ends the game
+// early.
+func (g *Game) StraightDrop(rotation, column int) (Cell, Commands, error) {
+	rotate := rotationCommands(g.Orientations().Canonical(rotation))
+
+	// Rotating first usually leaves more room, but the rotation may not
+	// fit at the spawn position. Then try moving over first.
+	// Rotations never move the pivot.
+	shift := shiftCommands(g.currUnit.Pivot.X, column)
+
+	landing, cs, err := g.dropWith(append(append(Commands(nil), rotate...), shift...))
+	if err == nil {
+		return landing, cs, nil
+	}
+
+	return g.dropWith(append(append(Commands(nil), shift...), rotate...))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestStraightDrop(t *testing.T) {
+	p := &InputProblem{
+		Units: []Unit{
+			// Horizontal pair, pivot on the left cell.
+			Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}},
+		},
+		Width:        6,
+		Height:       8,
+		Filled:       []Cell{{1, 7}},
+		SourceLength: 2,
+		SourceSeeds:  []uint64{0},
+	}
+
+	for _, column := range []int{0, 3, 4} {
+		g := GamesFromProblem(p)[0]
+
+		landing, cs, err := g.StraightDrop(0, column)
+		if err != nil {
+			t.Errorf("StraightDrop(0, %d) err got %v want nil", column, err)
+			continue
+		}
+
+		if landing.X != column {
+			t.Errorf("StraightDrop(0, %d) landed in column %d", column, landing.X)
+		}
+
+		for i, c := range cs {
+			locked, _, err := g.Update(c)
+			if err != nil {
+				t.Fatalf("Update(%s) err got %v want nil", c, err)
+			}
+			if last := i == len(cs)-1; locked != last {
+				t.Errorf("StraightDrop(0, %d) command %d locked got %v want %v", column, i, locked, last)
+			}
+		}
+
+		if !g.B.IsFilled(landing) {
+			t.Errorf("StraightDrop(0, %d) landing %+v not filled after drop", column, landing)
+		}
+	}
+
+	// Turned once, the pair points SE from the pivot and fits in column 0.
+	// Turned twice, it points SW, off the edge of the board.
+	g := GamesFromProblem(p)[0]
+	if _, _, err := g.StraightDrop(1, 0); err != nil {
+		t.Errorf("StraightDrop(1, 0) err got %v want nil", err)
+	}
+	if _, _, err := g.StraightDrop(2, 0); err == nil {
+		t.Errorf("StraightDrop(2, 0) err got nil want error")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"log"
+	"math"
+)
+
+// EndgameAI wraps another AI, and takes over with a search of every
+// placement sequence once few enough units remain that it can finish in
+// time.
+type EndgameAI struct {
+	ai AI
+
+	// units is the number of units left, including the current one, at
+	// which the search takes over.
+	units int
+
+	// game is the game being solved, once the search has taken over, and
+	// current the rest of the commands it found for every remaining unit.
+	game    *Game
+	current Commands
+	explained
+}
+
+func NewEndgameAI(ai AI, units int) AI {
+	return &EndgameAI{ai: ai, units: units}
+}
+
+// Game returns the Game used by the AI.
+// It may change after calls to Next().
+func (a *EndgameAI) Game() *Game {
+	if a.game != nil {
+		return a.game
+	}
+	return a.ai.Game()
+}
+
+// unitsLeft returns the number of units left to play, including the current
+// one.
+func (g *Game) unitsLeft() int {
+	return g.numUnits - g.unitsSent + 1
+}
+
+// FinishPath returns commands which lock the last unit, maximizing the power
+// score of the path plus the move score of the lock, and that value.
+func (g *Game) FinishPath() (Commands, float64, bool) {
+	return g.finishPath(2*(g.B.Width+g.B.Height), chantBeamWidth)
+}
+
+// finishPath is FinishPath, with paths of at most maxSteps moves, searched
+// with a beam of width paths.
+func (g *Game) finishPath(maxSteps, width int) (Commands, float64, bool) {
+	finish := func(p UnitPosition) (float64, bool) {
+		return g.LockScore(p), true
+	}
+	return g.chant(finish, g.B.Height, maxSteps, width)
+}
+
+// endgameSearch returns the commands for every remaining unit which lead to
+// the best final Objective, and that score. Each unit but the last is tried
+// at every placement, reached by its PhrasePath, and the last is locked by
+// FinishPath, so other paths to the same placements are not tried. The
+// current unit must not have moved yet. It returns false if limit expires
+// before the search is done.
+func endgameSearch(g *Game, limit *searchLimit) (Commands, float64, bool) {
+	if limit.Expired() {
+		return nil, 0, false
+	}
+
+	if g.unitsLeft() <= 1 {
+		cs, _, ok := g.FinishPath()
+		if !ok {
+			return nil, g.Objective(), true
+		}
+
+		f := g.Fork()
+		f.Play(cs)
+		return cs, f.Objective(), true
+	}
+
+	var best Commands
+	bestScore := math.Inf(-1)
+	for _, pl := range g.Placements() {
+		cs := g.PhrasePath(pl)
+
+		f := g.Fork()
+		_, done, err := f.Play(cs)
+		if err != nil {
+			continue
+		}
+
+		score := f.Objective()
+		if !done {
+			rest, s, ok := endgameSearch(f, limit)
+			if !ok {
+				return nil, 0, false
+			}
+			cs, score = append(cs, rest...), s
+		}
+
+		if score > bestScore {
+			best, bestScore = cs, score
+		}
+	}
+
+	return best, bestScore, true
+}
+
+// Explain implements Explainer, explaining the wrapped AI's decisions until
+// the search takes over.
+func (a *EndgameAI) Explain() *Explanation {
+	if a.game == nil {
+		return explain(a.ai)
+	}
+	return a.explained.Explain()
+}
+
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (a *EndgameAI) Next() (bool, error) {
+	if a.game == nil {
+		g := a.ai.Game()
+		if g.unitsLeft() > a.units || len(g.previousMoves) > 0 {
+			return a.ai.Next()
+		}
+
+		// If the search runs out of time, the wrapped AI plays this unit,
+		// and the search tries again, with one unit fewer, on the next.
+		log.Printf("Endgame search for last %d units", g.unitsLeft())
+		cs, score, ok := endgameSearch(g, newMoveLimit())
+		if !ok {
+			log.Printf("Endgame search out of time")
+			return a.ai.Next()
+		}
+
+		log.Printf("Endgame best score %f with %s", score, &cs)
+		a.game, a.current = g, cs
+		a.last = &Explanation{Chosen: Choice{
+			Move:  cs.String(),
+			Score: score,
+			Terms: map[string]float64{"endgame": score},
+		}}
+	}
+
+	// The search found nothing for a unit, which can only lock.
+	if len(a.current) == 0 {
+		a.current = Commands{directionToCommands[SE][0]}
+	}
+
+	c := a.current[0]
+	a.current = a.current[1:]
+
+	locked, done, err := a.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	return done, err
+}
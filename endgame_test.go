@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndgameSearch(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// The only line clear is dropping the pair into the gap in the bottom
+	// row.
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        5,
+		Height:       5,
+		Filled:       []Cell{{0, 4}, {3, 4}, {4, 4}},
+		SourceLength: 1,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	gap := UnitPosition{Pivot: Cell{1, 4}}
+	if got := g.LockScore(gap); got != 102 {
+		t.Errorf("LockScore(%+v) got %v want 102", gap, got)
+	}
+	if got := g.LockScore(g.currUnit); got != 2 {
+		t.Errorf("LockScore(%+v) got %v want 2", g.currUnit, got)
+	}
+
+	cs, score, ok := endgameSearch(g, nil)
+	if !ok {
+		t.Fatalf("endgameSearch with no limit ran out of time")
+	}
+	if score < 102 {
+		t.Errorf("endgameSearch score got %v want at least 102", score)
+	}
+
+	f := g.Fork()
+	if _, _, err := f.Play(cs); err != nil {
+		t.Fatalf("Play(%s) err %v", &cs, err)
+	}
+	if f.previousLinesCleared != 1 {
+		t.Errorf("Play(%s) cleared %d lines, want 1", &cs, f.previousLinesCleared)
+	}
+}
+
+func TestEndgameSearchTwoUnits(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// Either unit can clear the line, and the other just locks.
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        5,
+		Height:       5,
+		Filled:       []Cell{{0, 4}, {3, 4}, {4, 4}},
+		SourceLength: 2,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	cs, score, _ := endgameSearch(g, nil)
+	if score < 104 {
+		t.Errorf("endgameSearch score got %v want at least 104", score)
+	}
+
+	// The commands play both units.
+	f := g.Fork()
+	if _, done, err := f.Play(cs); err != nil || !done {
+		t.Fatalf("Play(%s) done %v err %v", &cs, done, err)
+	}
+	if got := f.Objective(); got != score {
+		t.Errorf("Play(%s) scored %v, want %v", &cs, got, score)
+	}
+
+	// Out of time, the search gives up.
+	expired := &searchLimit{deadline: time.Now()}
+	if _, _, ok := endgameSearch(g, expired); ok {
+		t.Errorf("endgameSearch with an expired limit finished")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An Evaluator scores games for the search AIs, so that heuristics can be
+// developed separately from search. lock is where the last unit locked, if
+// the move leading to g locked one, or nil.
+type Evaluator interface {
+	Evaluate(g *Game, lock *UnitPosition) float64
+}
+
+// A TermsEvaluator can break its scores down into named terms, which add
+// up to the score, to explain decisions.
+type TermsEvaluator interface {
+	Evaluator
+	Terms(g *Game, lock *UnitPosition) map[string]float64
+}
+
+// EvaluatorSetter is implemented by AIs which can search with any
+// Evaluator.
+type EvaluatorSetter interface {
+	SetEvaluator(e Evaluator)
+}
+
+// evaluators are the Evaluators which can be chosen by name.
+var evaluators = map[string]func() (Evaluator, error){
+	"score":    func() (Evaluator, error) { return ScoreEvaluator{}, nil },
+	"legacy":   func() (Evaluator, error) { return LegacyEvaluator{}, nil },
+	"weighted": func() (Evaluator, error) { return &WeightedEvaluator{Weights: defaultWeights}, nil },
+	"learned":  newLearnedEvaluator,
+}
+
+// NewEvaluator returns the Evaluator called name.
+func NewEvaluator(name string) (Evaluator, error) {
+	fn, ok := evaluators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown evaluator %q, want one of %s", name, evaluatorNames())
+	}
+	return fn()
+}
+
+// evaluatorNames lists the names of the evaluators, in order.
+func evaluatorNames() string {
+	var names []string
+	for n := range evaluators {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ScoreEvaluator scores games by their Objective alone.
+type ScoreEvaluator struct{}
+
+func (ScoreEvaluator) Evaluate(g *Game, lock *UnitPosition) float64 {
+	return g.Objective()
+}
+
+func (ScoreEvaluator) Terms(g *Game, lock *UnitPosition) map[string]float64 {
+	return scoreTerms(g)
+}
+
+// scoreTerms splits g's Objective into the line bonuses, as "combo", the
+// weighted power score, as "power", and the rest, as "score".
+func scoreTerms(g *Game) map[string]float64 {
+	t := map[string]float64{"score": g.moveScore - g.ComboScore()}
+	if c := g.ComboScore(); c != 0 {
+		t["combo"] = c
+	}
+	if power := powerWeight * float64(g.PowerScore()); power != 0 {
+		t["power"] = power
+	}
+	return t
+}
+
+// LegacyEvaluator is the original TreeAI heuristic. It adds depthWeight for
+// each row the current unit is down the board, and a bonus or penalty for
+// locking a unit, depending on whether it left a gap below.
+type LegacyEvaluator struct{}
+
+func (LegacyEvaluator) Evaluate(g *Game, lock *UnitPosition) float64 {
+	score, row, locked := legacyTerms(g, lock)
+	return score + row + locked
+}
+
+func (LegacyEvaluator) Terms(g *Game, lock *UnitPosition) map[string]float64 {
+	_, row, locked := legacyTerms(g, lock)
+	t := scoreTerms(g)
+	t["row"] = row
+	if lock != nil {
+		t["lock"] = locked
+	}
+	return t
+}
+
+func legacyTerms(g *Game, lock *UnitPosition) (score, row, locked float64) {
+	midY := 0.0
+	members := g.Cells(g.currUnit)
+	for _, c := range members {
+		midY += float64(c.Y)
+	}
+	midY /= float64(len(members))
+
+	if lock != nil {
+		if g.B.GapBelowAny(g.Cells(*lock)) {
+			locked = -10000
+		} else {
+			locked = 10000
+		}
+	}
+	return g.Objective(), depthWeight * midY, locked
+}
+
+// WeightedEvaluator scores games by the weighted sum of their Features.
+type WeightedEvaluator struct {
+	Weights Weights
+}
+
+func (e *WeightedEvaluator) Evaluate(g *Game, lock *UnitPosition) float64 {
+	return e.Weights.Score(g.Features())
+}
+
+func (e *WeightedEvaluator) Terms(g *Game, lock *UnitPosition) map[string]float64 {
+	f := g.Features()
+	t := make(map[string]float64)
+	for i, w := range e.Weights {
+		if v := w * f[i]; v != 0 {
+			t[featureNames[i]] = v
+		}
+	}
+	return t
+}
+
+// searchValue ranks g for searches over placements. Games which are over
+// have nothing left to lose, so they are worth their Objective, unless they
+// ended early.
+func searchValue(e Evaluator, g *Game, lock *UnitPosition, done bool) float64 {
+	if done {
+		if g.Died() {
+			return g.Objective() - deathPenalty
+		}
+		return g.Objective()
+	}
+	return e.Evaluate(g, lock)
+}
+
+// evalTerms breaks e's score for g down into terms, or returns it whole as
+// "eval" if e cannot.
+func evalTerms(e Evaluator, g *Game, lock *UnitPosition) map[string]float64 {
+	if te, ok := e.(TermsEvaluator); ok {
+		return te.Terms(g, lock)
+	}
+	return map[string]float64{"eval": e.Evaluate(g, lock)}
+}
+
+// searchTerms breaks searchValue down into terms.
+func searchTerms(e Evaluator, g *Game, lock *UnitPosition, done bool) map[string]float64 {
+	if done {
+		t := scoreTerms(g)
+		if g.Died() {
+			t["death"] = -deathPenalty
+		}
+		return t
+	}
+	return evalTerms(e, g, lock)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+var testWeighted = &WeightedEvaluator{Weights: defaultWeights}
+
+func TestNewEvaluator(t *testing.T) {
+	for name := range evaluators {
+		if name == "learned" {
+			// Needs a model, see TestLearnedEvaluator.
+			continue
+		}
+		if e, err := NewEvaluator(name); err != nil || e == nil {
+			t.Errorf("NewEvaluator(%q) got %v, %v", name, e, err)
+		}
+	}
+
+	if _, err := NewEvaluator("nonsense"); err == nil {
+		t.Errorf("NewEvaluator(nonsense) got no error")
+	}
+}
+
+func TestLegacyEvaluator(t *testing.T) {
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        4,
+		Height:       6,
+		Filled:       []Cell{{0, 5}},
+		SourceLength: 2,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	flat := UnitPosition{Pivot: Cell{1, 5}}
+	gap := UnitPosition{Pivot: Cell{0, 3}}
+
+	var e LegacyEvaluator
+	none := e.Evaluate(g, nil)
+	if got := e.Evaluate(g, &flat) - none; got != 10000 {
+		t.Errorf("lock with no gap got %v want 10000", got)
+	}
+	if got := e.Evaluate(g, &gap) - none; got != -10000 {
+		t.Errorf("lock over a gap got %v want -10000", got)
+	}
+}
+
+func TestSetEvaluator(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	for _, name := range []string{"treeai", "chanterai", "mcai", "beamai", "mcts", "annealai", "gaai"} {
+		a := NewAI(g.Fork(), name+":score", "")
+		var got Evaluator
+		switch a := a.(type) {
+		case *TreeAI:
+			got = a.eval
+		case *ChanterAI:
+			got = a.eval
+		case *MonteCarloid:
+			got = a.root.eval
+		case *BeamAI:
+			got = a.eval
+		case *MCTSAI:
+			got = a.eval
+		case *PlanAI:
+			got = a.eval
+		default:
+			t.Fatalf("%s: unexpected AI %T", name, a)
+		}
+		if _, ok := got.(ScoreEvaluator); !ok {
+			t.Errorf("%s: evaluator %T, want ScoreEvaluator", name, got)
+		}
+	}
+}
+
+func TestMCProbeEvaluator(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+	probe := func(g *Game, eval Evaluator, d Direction, spawned float64) float64 {
+		n := &MCNode{g: g.Fork(), probed: make([]*MCNode, int(NOP)+1), eval: eval}
+		_, v := n.tryDirection(d, 0, spawned, 0)
+		return v
+	}
+
+	// Moving down is only worth what the evaluator makes of it.
+	if got := probe(g, ScoreEvaluator{}, SW, 0); got != 0 {
+		t.Errorf("score probe SW got %v want 0", got)
+	}
+	if got := probe(g, LegacyEvaluator{}, SW, 0); got != depthWeight {
+		t.Errorf("legacy probe SW got %v want %v", got, depthWeight)
+	}
+
+	// A unit which locks banks what the evaluator gained on it since it
+	// spawned.
+	var e LegacyEvaluator
+	spawned := e.Evaluate(g, nil)
+	f := g.Fork()
+	d := SW
+	for i := 1; f.Fits(f.Orientations().Move(f.currUnit, d)); i++ {
+		f.Update(directionToCommands[d][0])
+		d = []Direction{SW, SE}[i%2]
+	}
+	before := e.Evaluate(f, nil)
+	after := f.Fork()
+	if locked, _, _ := after.Update(directionToCommands[d][0]); !locked {
+		t.Fatalf("moving %v at the bottom did not lock", d)
+	}
+	if got, want := probe(f, e, d, spawned), before-spawned+e.Evaluate(after, nil); got != want {
+		t.Errorf("legacy probe locking got %v want %v", got, want)
+	}
+}
+
+func TestExpandUnweighted(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	// Without weights, nothing is dominated, so only duplicate boards
+	// are left out.
+	boards := make(map[uint64]bool)
+	for _, pl := range g.Placements() {
+		f := g.Fork()
+		if _, _, err := f.Play(pl.Commands); err == nil {
+			boards[f.B.hash] = true
+		}
+	}
+
+	kept := g.Expand(ScoreEvaluator{})
+	if len(kept) != len(boards) {
+		t.Errorf("kept %d placements, want %d", len(kept), len(boards))
+	}
+	for _, e := range kept {
+		if !e.Done && e.Value != e.Game.Score() {
+			t.Errorf("placement %+v value %v want score %v", e.Position, e.Value, e.Game.Score())
+		}
+	}
+}
+
+func TestEvaluatorTerms(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+	ps := g.Placements()
+	lock := ps[0].Position
+	g.Play(ps[0].Commands)
+
+	// Earn a line bonus, which is a term of its own.
+	g.updateScore(2)
+	g.updateScore(1)
+	if c := (ScoreEvaluator{}).Terms(g, &lock)["combo"]; c != g.ComboScore() || c == 0 {
+		t.Errorf("combo term %v, want %v", c, g.ComboScore())
+	}
+
+	// And a weighted power score, which is a term too.
+	g.powerWordCount["ei!"]++
+	defer func(w float64) { powerWeight = w }(powerWeight)
+	powerWeight = 3
+	if p := (ScoreEvaluator{}).Terms(g, &lock)["power"]; p == 0 || p != 3*float64(g.PowerScore()) {
+		t.Errorf("power term %v, want 3 times %v", p, g.PowerScore())
+	}
+
+	learned := &LinearModel{Bias: 3}
+	learned.Weights[featHoles] = -2
+	learned.Weights[inputUnitsLeft] = 7
+
+	for _, e := range []TermsEvaluator{ScoreEvaluator{}, LegacyEvaluator{}, testWeighted, &LearnedEvaluator{Model: learned}} {
+		sum := 0.0
+		for _, v := range e.Terms(g, &lock) {
+			sum += v
+		}
+		if want := e.Evaluate(g, &lock); math.Abs(sum-want) > 1e-9 {
+			t.Errorf("%T terms add up to %v, want %v", e, sum, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+)
+
+// Expansion is a placement played out from a game.
+type Expansion struct {
+	Placement
+
+	// Game is the game after the placement locks.
+	Game *Game
+	Done bool
+
+	// Value ranks Game, by searchValue.
+	Value float64
+
+	// Features of Game, if it was expanded with a WeightedEvaluator and
+	// is not done.
+	Features Features
+}
+
+// Dominates returns whether a is at least as good as b on every term of w,
+// and better on at least one, so b can never score more than a.
+func (w *Weights) Dominates(a, b Features) bool {
+	better := false
+	for i := range a {
+		ta, tb := w[i]*a[i], w[i]*b[i]
+		if ta < tb {
+			return false
+		}
+		if ta > tb {
+			better = true
+		}
+	}
+	return better
+}
+
+// Expand plays every placement from g, valuing each with eval, and leaving
+// out those which cannot be best. Of the placements which leave the same
+// board, only the one whose PhrasePath gains the most power is kept. If eval
+// is a WeightedEvaluator, placements which are worse than another on every
+// term of its weights are also left out. Games which are done are never
+// compared on features.
+func (g *Game) Expand(eval Evaluator) []Expansion {
+	type boardKey struct {
+		hash uint64
+		done bool
+	}
+
+	var all []Expansion
+
+	// What the PhrasePath of each placement in all gains. Chanting is
+	// slow, so it is only found for placements which need comparing.
+	var gains []float64
+	gain := func(i int) float64 {
+		if math.IsNaN(gains[i]) {
+			gains[i] = g.PhraseGain(g.PhrasePath(all[i].Placement))
+		}
+		return gains[i]
+	}
+
+	seen := make(map[boardKey]int)
+	for _, pl := range g.Placements() {
+		f := g.Fork()
+		_, done, err := f.Play(pl.Commands)
+		if err != nil {
+			continue
+		}
+
+		e := Expansion{Placement: pl, Game: f, Done: done}
+
+		// The same board leaves the same game to come, except for the
+		// power points on the way. The games here were played along
+		// shortest paths, which seldom spell phrases, so compare the
+		// paths the placements would really be played along.
+		k := boardKey{f.B.hash, done}
+		if i, ok := seen[k]; ok {
+			if pg := g.PhraseGain(g.PhrasePath(pl)); pg > gain(i) {
+				all[i], gains[i] = e, pg
+			}
+			continue
+		}
+		seen[k] = len(all)
+
+		all = append(all, e)
+		gains = append(gains, math.NaN())
+	}
+
+	var w *Weights
+	if we, ok := eval.(*WeightedEvaluator); ok {
+		w = &we.Weights
+	}
+
+	for i := range all {
+		e := &all[i]
+		if w != nil && !e.Done {
+			// The same as searchValue, without finding the
+			// features twice.
+			e.Features = e.Game.Features()
+			e.Value = w.Score(e.Features)
+		} else {
+			e.Value = searchValue(eval, e.Game, &e.Position, e.Done)
+		}
+	}
+
+	if w == nil {
+		return all
+	}
+
+	var kept []Expansion
+	for i, e := range all {
+		dominated := false
+		for j, o := range all {
+			if i != j && !e.Done && !o.Done && w.Dominates(o.Features, e.Features) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			kept = append(kept, e)
+		}
+	}
+
+	return kept
+}
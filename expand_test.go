@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDominates(t *testing.T) {
+	w := Weights{featScore: 1, featHoles: -1}
+
+	cases := []struct {
+		a, b Features
+		want bool
+	}{
+		{Features{featScore: 2}, Features{featScore: 1}, true},
+		{Features{featScore: 1}, Features{featScore: 1}, false},
+		{Features{featScore: 1, featHoles: 0}, Features{featScore: 1, featHoles: 2}, true},
+		{Features{featScore: 2, featHoles: 1}, Features{featScore: 1, featHoles: 0}, false},
+		// Unweighted features do not count.
+		{Features{featScore: 2, featHeight: 5}, Features{featScore: 1}, true},
+	}
+	for _, c := range cases {
+		if got := w.Dominates(c.a, c.b); got != c.want {
+			t.Errorf("Dominates(%v, %v) got %v want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		if p.Width*p.Height > 400 {
+			continue
+		}
+
+		g := GamesFromProblem(p)[0]
+		kept := g.Expand(testWeighted)
+
+		boards := make(map[uint64]bool)
+		best := 0.0
+		for i, e := range kept {
+			if boards[e.Game.B.hash] {
+				t.Errorf("problem %d: board from %+v kept twice", p.Id, e.Position)
+			}
+			boards[e.Game.B.hash] = true
+
+			if v := e.Value; i == 0 || v > best {
+				best = v
+			}
+		}
+
+		// Nothing left out could have been better.
+		for _, pl := range g.Placements() {
+			f := g.Fork()
+			_, done, err := f.Play(pl.Commands)
+			if err != nil || done {
+				continue
+			}
+			if v := defaultWeights.Score(f.Features()); v > best {
+				t.Errorf("problem %d: placement %+v value %v better than all kept %v", p.Id, pl.Position, v, best)
+			}
+		}
+	}
+}
+
+func TestExpandPhraseGain(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// Filling 1 and 2 of the bottom row clears it, leaving the same board
+	// as filling 1 and 2 of the row above.
+	//
+	//    . . .
+	//     . . .
+	//    x . .
+	//     x . x
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        3,
+		Height:       4,
+		Filled:       []Cell{{0, 2}, {0, 3}, {2, 3}},
+		SourceLength: 2,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	kept := make(map[uint64]Placement)
+	for _, e := range g.Expand(ScoreEvaluator{}) {
+		kept[e.Game.B.hash] = e.Placement
+	}
+
+	// The kept placement of each board gains as much power as any other
+	// leaving it.
+	collisions := 0
+	for _, pl := range g.Placements() {
+		f := g.Fork()
+		if _, _, err := f.Play(pl.Commands); err != nil {
+			continue
+		}
+		k, ok := kept[f.B.hash]
+		if !ok || k.Position == pl.Position {
+			continue
+		}
+		collisions++
+
+		if got, other := g.PhraseGain(g.PhrasePath(k)), g.PhraseGain(g.PhrasePath(pl)); other > got {
+			t.Errorf("kept %+v gaining %v over %+v gaining %v", k.Position, got, pl.Position, other)
+		}
+	}
+	if collisions == 0 {
+		t.Errorf("no placements left the same board")
+	}
+}
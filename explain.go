@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// A Choice is a move an AI considered.
+type Choice struct {
+	Move string `json:"move"`
+	// Score is what the search ranked the move by.
+	Score float64 `json:"score"`
+	// Terms break the score down by name. Searches which do not add up
+	// scores, like MCTS, give the terms of the position the move leads
+	// to instead.
+	Terms map[string]float64 `json:"terms,omitempty"`
+	// Visits is how often a sampling search tried the move.
+	Visits int `json:"visits,omitempty"`
+}
+
+func (c *Choice) String() string {
+	var names []string
+	for n := range c.Terms {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s (%.1f", c.Move, c.Score)
+	if c.Visits > 0 {
+		fmt.Fprintf(&b, " in %d visits", c.Visits)
+	}
+	for i, n := range names {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%s %.1f", sep, n, c.Terms[n])
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// An Explanation records why an AI made a decision: the move it chose, and
+// the best of the rest, if there was one.
+type Explanation struct {
+	Chosen   Choice  `json:"chosen"`
+	RunnerUp *Choice `json:"runnerUp,omitempty"`
+}
+
+func (e *Explanation) String() string {
+	if e.RunnerUp == nil {
+		return fmt.Sprintf("chose %v", &e.Chosen)
+	}
+	return fmt.Sprintf("chose %v over %v", &e.Chosen, e.RunnerUp)
+}
+
+// An Explainer is an AI which can explain its decisions.
+type Explainer interface {
+	// Explain returns why the AI made the decision it made in the last
+	// call to Next, or nil if it did not make one.
+	Explain() *Explanation
+}
+
+// explained implements Explainer for AIs which embed it, holding the last
+// decision until it is explained.
+type explained struct {
+	last *Explanation
+}
+
+func (x *explained) Explain() *Explanation {
+	e := x.last
+	x.last = nil
+	return e
+}
+
+// explain returns why a made its last decision, if it can say.
+func explain(a AI) *Explanation {
+	if x, ok := a.(Explainer); ok {
+		return x.Explain()
+	}
+	return nil
+}
+
+// traceEntry is a line of the -trace file.
+type traceEntry struct {
+	Problem int    `json:"problem"`
+	Seed    uint64 `json:"seed"`
+	AI      string `json:"ai"`
+	Unit    int    `json:"unit"`
+	*Explanation
+}
+
+// tracer writes each explained decision to w, one JSON object per line.
+type tracer struct {
+	e *json.Encoder
+}
+
+func newTracer(w io.Writer) *tracer {
+	return &tracer{e: json.NewEncoder(w)}
+}
+
+// Trace writes e, for unit unit of the game with seed from problem, as
+// played by ai. It is a no-op on a nil tracer.
+func (t *tracer) Trace(problem int, seed uint64, ai string, unit int, e *Explanation) error {
+	if t == nil {
+		return nil
+	}
+	return t.e.Encode(traceEntry{Problem: problem, Seed: seed, AI: ai, Unit: unit, Explanation: e})
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestExplanationString(t *testing.T) {
+	e := &Explanation{
+		Chosen:   Choice{Move: "SE", Score: 12, Terms: map[string]float64{"score": 2, "depth": 10}},
+		RunnerUp: &Choice{Move: "W", Score: 3.25, Visits: 4},
+	}
+	want := "chose SE (12.0: depth 10.0, score 2.0) over W (3.2 in 4 visits)"
+	if got := e.String(); got != want {
+		t.Errorf("String() got %q want %q", got, want)
+	}
+}
+
+// termsSum returns the sum of c's terms.
+func termsSum(c *Choice) float64 {
+	sum := 0.0
+	for _, v := range c.Terms {
+		sum += v
+	}
+	return sum
+}
+
+func TestExplain(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// These add up scores, so the terms should too.
+	for _, name := range []string{"treeai", "chanterai", "beamai", "lookaheadai"} {
+		a := NewAI(GamesFromProblem(planProblem())[0], name, "")
+		if _, err := a.Next(); err != nil {
+			t.Fatalf("%s: Next err %v", name, err)
+		}
+
+		e := explain(a)
+		if e == nil || e.RunnerUp == nil {
+			t.Fatalf("%s: explanation %v, want a choice and runner-up", name, e)
+		}
+		if e.Chosen.Score < e.RunnerUp.Score {
+			t.Errorf("%s: chose %v over better %v", name, &e.Chosen, e.RunnerUp)
+		}
+		for _, c := range []*Choice{&e.Chosen, e.RunnerUp} {
+			if sum := termsSum(c); math.Abs(sum-c.Score) > 1e-6 {
+				t.Errorf("%s: %v terms add up to %v", name, c, sum)
+			}
+		}
+
+		if e := explain(a); e != nil {
+			t.Errorf("%s: explained %v twice", name, e)
+		}
+	}
+}
+
+func TestExplainRollouts(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	// The runner-up is the best command whose rollout died, if any did.
+	a := NewAI(GamesFromProblem(planProblem())[0], "cmc", "")
+	for i := 0; i < 5; i++ {
+		if _, err := a.Next(); err != nil {
+			t.Fatalf("Next err %v", err)
+		}
+
+		e := explain(a)
+		if e == nil {
+			t.Fatalf("step %d: no explanation", i)
+		}
+		if sum := termsSum(&e.Chosen); math.Abs(sum-e.Chosen.Score) > 1e-6 {
+			t.Errorf("step %d: %v terms add up to %v", i, &e.Chosen, sum)
+		}
+		if e.RunnerUp != nil && e.RunnerUp.Terms["death"] == 0 {
+			t.Errorf("step %d: runner-up %v did not die", i, e.RunnerUp)
+		}
+	}
+}
+
+func TestExplainWrapped(t *testing.T) {
+	g := GamesFromProblem(planProblem())[0]
+	a := NewEndgameAI(NewBeamAI(g, ""), 1)
+
+	// The beam explains until the endgame search takes over for the last
+	// unit.
+	for i := 0; ; i++ {
+		left := a.Game().unitsLeft()
+		done, err := a.Next()
+		if err != nil {
+			t.Fatalf("Next err %v", err)
+		}
+
+		if e := explain(a); e != nil {
+			_, endgame := e.Chosen.Terms["endgame"]
+			if endgame != (left == 1) {
+				t.Errorf("step %d with %d units left: explained %v", i, left, e)
+			}
+		}
+		if done {
+			break
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var b bytes.Buffer
+	tr := newTracer(&b)
+	e := &Explanation{Chosen: Choice{Move: "SE", Score: 1, Terms: map[string]float64{"score": 1}}}
+	if err := tr.Trace(3, 42, "treeai", 7, e); err != nil {
+		t.Fatalf("Trace err %v", err)
+	}
+
+	var got struct {
+		Problem  int
+		Seed     uint64
+		AI       string
+		Unit     int
+		Chosen   Choice
+		RunnerUp *Choice
+	}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("trace %q: %v", b.String(), err)
+	}
+	if got.Problem != 3 || got.Seed != 42 || got.AI != "treeai" || got.Unit != 7 || got.Chosen.Move != "SE" || got.Chosen.Terms["score"] != 1 || got.RunnerUp != nil {
+		t.Errorf("trace got %+v", got)
+	}
+
+	// A nil tracer traces nothing.
+	var none *tracer
+	if err := none.Trace(3, 42, "treeai", 7, e); err != nil {
+		t.Errorf("nil Trace err %v", err)
+	}
+}
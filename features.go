@@ -0,0 +1,172 @@
+package main
+
+// Board features used to rank positions. Each is oriented so that it is
+// bad to have lots of it, except for the score, the combo score and the
+// chain. Selfplay samples store features by position, so new ones go at
+// the end.
+const (
+	featScore = iota
+	featHoles
+	featHeight
+	featBumpiness
+	featEmptyRows
+	featBlocked
+	featStarved
+	featCombo
+	featChain
+	featReady
+	numFeatures
+)
+
+var featureNames = [numFeatures]string{
+	"score",
+	"holes",
+	"height",
+	"bumpiness",
+	"emptyRows",
+	"blocked",
+	"starved",
+	"combo",
+	"chain",
+	"ready",
+}
+
+// Features is a game summarized by the features above.
+type Features [numFeatures]float64
+
+// Weights scores Features as their dot product.
+type Weights [numFeatures]float64
+
+var defaultWeights = Weights{
+	featScore:     1,
+	featHoles:     -40,
+	featHeight:    -8,
+	featBumpiness: -4,
+	featEmptyRows: -2,
+	featBlocked:   -200,
+	featStarved:   -10,
+	featCombo:     1,
+	featChain:     20,
+	featReady:     10,
+}
+
+// Score returns the weighted sum of f.
+func (w *Weights) Score(f Features) float64 {
+	s := 0.0
+	for i := range f {
+		s += w[i] * f[i]
+	}
+	return s
+}
+
+// columnTops returns the row of the highest filled cell in each column, or
+// the board height for empty columns.
+func (b *Board) columnTops() []int {
+	tops := make([]int, b.Width)
+	for x := 0; x < b.Width; x++ {
+		tops[x] = b.Height
+		for y := 0; y < b.Height; y++ {
+			if b.Cells[x][y].Filled {
+				tops[x] = y
+				break
+			}
+		}
+	}
+	return tops
+}
+
+// Holes counts the empty cells with a filled cell resting on top of them.
+func (b *Board) Holes() int {
+	holes := 0
+	for x := 0; x < b.Width; x++ {
+		for y := 1; y < b.Height; y++ {
+			c := Cell{x, y}
+			if b.IsFilled(c) {
+				continue
+			}
+
+			for _, d := range []Direction{NW, NE} {
+				above := c.Translate(d)
+				if b.InBounds(above) && b.IsFilled(above) {
+					holes++
+					break
+				}
+			}
+		}
+	}
+	return holes
+}
+
+// Features summarizes g.
+func (g *Game) Features() Features {
+	var f Features
+	b := g.B
+
+	f[featScore] = g.Objective() - g.ComboScore()
+	f[featHoles] = float64(b.Holes())
+
+	tops := b.columnTops()
+	top := b.Height
+	for x, t := range tops {
+		if t < top {
+			top = t
+		}
+		if x > 0 {
+			f[featBumpiness] += float64(abs(t - tops[x-1]))
+		}
+	}
+	f[featHeight] = float64(b.Height - top)
+
+	// Empty cells in rows which have been started, which all need
+	// filling before they clear.
+	for y := top; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if !b.Cells[x][y].Filled {
+				f[featEmptyRows]++
+			}
+		}
+	}
+	f[featEmptyRows] /= float64(b.Width)
+
+	f[featBlocked] = float64(g.SpawnBlockage(0).Blocked)
+	f[featStarved] = g.starved()
+	f[featCombo] = g.ComboScore()
+	f[featChain] = g.chain()
+	f[featReady] = float64(g.readyRows())
+
+	return f
+}
+
+// chain returns how many tenths of its points the next lock to clear lines
+// would earn as a line bonus, for having cleared several on the last lock.
+func (g *Game) chain() float64 {
+	if g.previousLinesCleared < 2 {
+		return 0
+	}
+	return float64(g.previousLinesCleared - 1)
+}
+
+// readyRows counts the started rows which the biggest unit could fill.
+// Clearing several together earns more, and sets up a line bonus.
+func (g *Game) readyRows() int {
+	size := 0
+	for _, u := range g.units {
+		if len(u.Members) > size {
+			size = len(u.Members)
+		}
+	}
+
+	ready := 0
+	for y := 0; y < g.B.Height; y++ {
+		empty := 0
+		for x := 0; x < g.B.Width && empty <= size; x++ {
+			if !g.B.Cells[x][y].Filled {
+				empty++
+			}
+		}
+		if empty > 0 && empty <= size && empty < g.B.Width {
+			ready++
+		}
+	}
+	return ready
+}
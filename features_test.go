@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	// Row 3 has one cell covered by the cells in row 2 on either side.
+	//
+	//    . . . . .
+	//     . . . . .
+	//    . x . x .
+	//     x . x x x
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}}, Pivot: Cell{0, 0}}},
+		Width:        5,
+		Height:       4,
+		Filled:       []Cell{{1, 2}, {3, 2}, {0, 3}, {2, 3}, {3, 3}, {4, 3}},
+		SourceLength: 1,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	f := g.Features()
+	want := map[int]float64{
+		featScore:     0,
+		featCombo:     0,
+		featChain:     0,
+		featReady:     1,
+		featHoles:     1,
+		featHeight:    2,
+		featBumpiness: 4,
+		featEmptyRows: 4.0 / 5,
+		featBlocked:   0,
+	}
+	for i, w := range want {
+		if f[i] != w {
+			t.Errorf("feature %s got %v want %v", featureNames[i], f[i], w)
+		}
+	}
+}
+
+func TestComboScore(t *testing.T) {
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {0, 1}}, Pivot: Cell{0, 0}}},
+		Width:        4,
+		Height:       4,
+		SourceLength: 3,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	// Two lines, then two more with a tenth of 302 points bonus, then one
+	// with a tenth of 102.
+	for i, want := range []struct{ score, combo, chain float64 }{
+		{302, 0, 1},
+		{302 + 302 + 30, 30, 1},
+		{302 + 302 + 30 + 102 + 10, 40, 0},
+	} {
+		lines := 2
+		if i == 2 {
+			lines = 1
+		}
+		g.updateScore(lines)
+
+		f := g.Features()
+		if f[featScore]+f[featCombo] != want.score || f[featCombo] != want.combo || f[featChain] != want.chain {
+			t.Errorf("lock %d: score %v combo %v chain %v, want %+v", i, f[featScore]+f[featCombo], f[featCombo], f[featChain], want)
+		}
+		if g.Score() != want.score {
+			t.Errorf("lock %d: Score() %v want %v", i, g.Score(), want.score)
+		}
+	}
+}
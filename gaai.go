@@ -0,0 +1,165 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+var (
+	// gaPopulation is the number of plans in each generation.
+	gaPopulation = 24
+	// gaElite is the number of best plans carried over unchanged.
+	gaElite = 2
+	// gaTournament is the number of plans competing to be a parent.
+	gaTournament = 3
+	// gaMutations is the most genes changed in a child.
+	gaMutations = 3
+)
+
+// genome is a plan with its fitness.
+type genome struct {
+	r       *planResult
+	fitness float64
+}
+
+type byFitness []genome
+
+func (s byFitness) Len() int           { return len(s) }
+func (s byFitness) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byFitness) Less(i, j int) bool { return s[i].fitness > s[j].fitness }
+
+// planFitness returns the final score of the game played by r, which is a
+// proxy for the plan played with chanting, as planResult.Score is.
+func planFitness(r *planResult) float64 {
+	g := r.final.Fork()
+	g.WriteFinalCommands()
+	return g.FinalScore()
+}
+
+// child is a plan to be played from unit from of base.
+type child struct {
+	base *planResult
+	from int
+	plan []int
+}
+
+// crossover returns a child with the plan of a up to a random unit, and the
+// plan of b after. It only needs replaying from that unit.
+func crossover(a, b *planResult) child {
+	n := len(a.placed)
+	if len(b.plan) > n {
+		n = len(b.plan)
+	}
+	k := rand.Intn(n + 1)
+	if k > len(a.games)-1 {
+		k = len(a.games) - 1
+	}
+
+	plan := make([]int, k)
+	copy(plan, a.plan)
+	if k < len(b.plan) {
+		plan = append(plan, b.plan[k:]...)
+	}
+	return child{base: a, from: k, plan: plan}
+}
+
+// mutate changes up to gaMutations random genes of c, among n units.
+func (c *child) mutate(n int) {
+	for m := rand.Intn(gaMutations + 1); m > 0; m-- {
+		k := rand.Intn(n)
+		for len(c.plan) <= k {
+			c.plan = append(c.plan, 0)
+		}
+		c.plan[k] = rand.Intn(annealMaxRank + 1)
+
+		if k < c.from {
+			c.from = k
+		}
+	}
+}
+
+// tournament returns the fittest of gaTournament random plans.
+func tournament(pop []genome) genome {
+	best := pop[rand.Intn(len(pop))]
+	for i := 1; i < gaTournament; i++ {
+		if g := pop[rand.Intn(len(pop))]; g.fitness > best.fitness {
+			best = g
+		}
+	}
+	return best
+}
+
+// evaluate replays children on -c workers.
+func evaluate(children []child) []genome {
+	out := make([]genome, len(children))
+	parallel(identityOrder(len(children)), func(i int) {
+		c := children[i]
+		r := replayPlan(c.base, c.from, c.plan)
+		out[i] = genome{r: r, fitness: planFitness(r)}
+	})
+	return out
+}
+
+// evolve improves the plan for g, ranking placements with eval, with a
+// genetic algorithm until the budget runs out, returning the best plan
+// found, and the greedy plan.
+func evolve(g *Game, eval Evaluator, budget time.Duration) (best, greedy *planResult) {
+	end := time.Now().Add(budget)
+	if !deadline.IsZero() && deadline.Before(end) {
+		end = deadline
+	}
+
+	greedy = replayPlan(newPlan(g, eval), 0, nil)
+	n := len(greedy.placed)
+	log.Printf("Greedy plan scores %f over %d units", greedy.Score(), n)
+	if n == 0 {
+		// There are no genes to mutate.
+		return greedy, greedy
+	}
+
+	// Start from the greedy plan and mutations of it.
+	children := []child{{base: newPlan(g, eval)}}
+	for len(children) < gaPopulation {
+		c := child{base: greedy, from: len(greedy.games) - 1}
+		c.mutate(n)
+		children = append(children, c)
+	}
+	pop := evaluate(children)
+	sort.Stable(byFitness(pop))
+
+	generation := 0
+	for time.Now().Before(end) {
+		generation++
+
+		children = children[:0]
+		for len(children) < gaPopulation-gaElite {
+			c := crossover(tournament(pop).r, tournament(pop).r)
+			c.mutate(n)
+			children = append(children, c)
+		}
+
+		next := append([]genome(nil), pop[:gaElite]...)
+		next = append(next, evaluate(children)...)
+		sort.Stable(byFitness(next))
+
+		if next[0].fitness > pop[0].fitness {
+			log.Printf("Generation %d: best %f", generation, next[0].fitness)
+		}
+		pop = next
+	}
+
+	log.Printf("Evolved %d generations, best %f", generation, pop[0].fitness)
+	return pop[0].r, greedy
+}
+
+func NewGeneticAI(g *Game, _ string) AI {
+	return &PlanAI{
+		game: g,
+		eval: &WeightedEvaluator{Weights: defaultWeights},
+		solve: func(g *Game, eval Evaluator) (*planResult, *planResult) {
+			return evolve(g, eval, *gaTime)
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrossover(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+	a := replayPlan(newPlan(g, testWeighted), 0, []int{1, 1, 1, 1, 1, 1, 1, 1})
+	b := replayPlan(newPlan(g, testWeighted), 0, []int{2, 2, 2, 2, 2, 2, 2, 2})
+
+	for i := 0; i < 20; i++ {
+		c := crossover(a, b)
+		for k, r := range c.plan {
+			want := 2
+			if k < c.from {
+				want = a.plan[k]
+			}
+			if r != want {
+				t.Fatalf("crossover at %d plan %v", c.from, c.plan)
+			}
+		}
+
+		// The child replays from a, and matches replaying from the
+		// start.
+		part := replayPlan(c.base, c.from, c.plan)
+		whole := replayPlan(newPlan(g, testWeighted), 0, c.plan)
+		if part.Score() != whole.Score() {
+			t.Errorf("crossover at %d scored %v, from the start %v", c.from, part.Score(), whole.Score())
+		}
+	}
+}
+
+func TestEvolve(t *testing.T) {
+	defer func(c int) { *cpus = c }(*cpus)
+	*cpus = 2
+
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	best, greedy := evolve(g, testWeighted, 100*time.Millisecond)
+	if want := replayPlan(newPlan(g, testWeighted), 0, nil); planFitness(greedy) != planFitness(want) {
+		t.Errorf("evolve returned greedy plan scoring %v, want %v", planFitness(greedy), planFitness(want))
+	}
+	if planFitness(best) < planFitness(greedy) {
+		t.Errorf("evolve scored %v, less than greedy %v", planFitness(best), planFitness(greedy))
+	}
+}
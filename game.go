@@ -33,6 +33,8 @@ func (l *GameLCG) Next() uint64 {
 type Game struct {
 	// Accumulated move score so far.
 	moveScore float64
+	// The part of moveScore earned by line bonuses.
+	comboScore     float64
 	powerWordCount map[string]int
 
 	// All previous commands sent to the game.
@@ -42,26 +44,35 @@ type Game struct {
 
 	B         *Board
 	units     []Unit
+	orients   []Orientations
+	spawns    []UnitPosition
 	lcg       GameLCG
 	numUnits  int
 	unitsSent int
 
+	// Set when a unit could not spawn, ending the game early.
+	spawnFailed bool
+
 	// Keep track of moves for current unit.
-	currUnit             *Unit
-	previousMoves        []*Unit
+	currUnit             UnitPosition
+	previousMoves        []PositionKey
 	previousLinesCleared int
 }
 
 func (g *Game) Fork() *Game {
 	n := &Game{
 		moveScore:            g.moveScore,
+		comboScore:           g.comboScore,
 		B:                    g.B.Fork(),
 		units:                g.units,
+		orients:              g.orients,
+		spawns:               g.spawns,
 		lcg:                  g.lcg,
 		numUnits:             g.numUnits,
 		unitsSent:            g.unitsSent,
-		currUnit:             g.currUnit.DeepCopy(),
-		previousMoves:        CopyUnits(g.previousMoves),
+		spawnFailed:          g.spawnFailed,
+		currUnit:             g.currUnit,
+		previousMoves:        append([]PositionKey(nil), g.previousMoves...),
 		previousLinesCleared: g.previousLinesCleared,
 	}
 
@@ -80,12 +91,17 @@ func GamesFromProblem(p *InputProblem) []*Game {
 	nseeds := len(p.SourceSeeds)
 	games := make([]*Game, nseeds)
 
+	orients := OrientationTable(p.Units)
+	spawns := spawnTable(p.Units, p.Width)
+
 	for i, s := range p.SourceSeeds {
 		g := &Game{
-			B:        NewBoard(p.Width, p.Height, p.Filled),
-			lcg:      NewLCG(s),
-			units:    p.Units,
-			numUnits: p.SourceLength,
+			B:              NewBoard(p.Width, p.Height, p.Filled),
+			lcg:            NewLCG(s),
+			units:          p.Units,
+			orients:        orients,
+			spawns:         spawns,
+			numUnits:       p.SourceLength,
 			powerWordCount: make(map[string]int),
 		}
 
@@ -117,66 +133,127 @@ func (g *Game) String() string {
 }`, g.Score(), g.B.StringLevel(2), g.units, g.lcg, g.numUnits, g.unitsSent, g.currUnit, g.previousMoves)
 }
 
-func (g *Game) LockUnit(u *Unit) {
-	for _, c := range u.Members {
-		g.B.MarkFilled(c)
+func (g *Game) LockUnit(p UnitPosition) {
+	o := g.Orientation(p)
+	pc := p.Pivot.ToCube()
+	for _, v := range o.Offsets[p.Rotation] {
+		g.B.MarkFilled(pc.Add(v).ToCell())
 	}
 }
 
-func (g *Game) NextUnit() (*Unit, bool) {
+// NextUnit returns the spawn position of the next unit.
+func (g *Game) NextUnit() (UnitPosition, bool) {
 	if g.unitsSent >= g.numUnits {
-		return nil, false
+		return UnitPosition{}, false
 	}
 
 	rand := g.lcg.Next()
 	idx := int(rand) % len(g.units)
-	templUnit := &g.units[idx]
-
-	// Do a deep copy of the chosen Unit.
-	r := templUnit.DeepCopy()
 
 	g.unitsSent++
 
-	return r, true
+	return g.spawns[idx], true
 }
 
-func (g *Game) placeUnit(u *Unit) bool {
-	l, r := u.Bounds()
+func (g *Game) placeUnit(p UnitPosition) bool {
+	return g.Fits(p)
+}
 
-	uwidth := r.X - l.X + 1
-	extraSpace := g.B.Width - uwidth
+// Orientations returns the rotations of the current unit's template.
+func (g *Game) Orientations() *Orientations {
+	return g.Orientation(g.currUnit)
+}
 
-	// Center up, rounding down, leaving less space on the left.
-	rightShift := extraSpace / 2
-	// If the leftmost cell is not at zero, we don't need to shift as much.
-	rightShift -= l.X
-	for i := range u.Members {
-		u.Members[i].X += rightShift
+// redundantMove returns whether d is a rotation which can only revisit the
+// current position, or covers the same cells as the opposite rotation.
+// Searches may skip these moves.
+func (g *Game) redundantMove(d Direction) bool {
+	switch g.Orientations().Period {
+	case 1:
+		return d == CW || d == CCW
+	case 2:
+		return d == CCW
 	}
-	u.Pivot.X += rightShift
+	return false
+}
+
+// Died returns whether the game ended because a unit could not spawn,
+// rather than running out of units.
+func (g *Game) Died() bool {
+	return g.spawnFailed
+}
 
-	return g.B.IsValid(u)
+// SpawnBlockage reports which of the problem's templates could spawn on the
+// current board, and how many filled cells are within k rows of the spawn
+// area. AIs can use this to avoid locks which will kill the game soon.
+func (g *Game) SpawnBlockage(k int) SpawnReport {
+	return g.B.SpawnBlockage(g.units, k)
 }
 
 // updateScore computes the new Game moves score, and remembers linesCleared as
 // previous lines cleared. The power score is computed on-demand with Score()
 // or PowerScore().
 func (g *Game) updateScore(linesCleared int) {
+	points, bonus := g.linePoints(linesCleared)
+	g.moveScore += points + bonus
+	g.comboScore += bonus
+	g.previousLinesCleared = linesCleared
+}
+
+// movePoints returns the move score for locking the current unit and
+// clearing linesCleared lines.
+func (g *Game) movePoints(linesCleared int) float64 {
+	points, bonus := g.linePoints(linesCleared)
+	return points + bonus
+}
+
+// linePoints returns the points for locking the current unit and clearing
+// linesCleared lines, and the line bonus on top of them, earned when the
+// previous lock cleared more than one line.
+func (g *Game) linePoints(linesCleared int) (points, bonus float64) {
 	ls := float64(linesCleared)
 	lsOld := float64(g.previousLinesCleared)
-	size := float64(g.currUnit.Size())
+	size := float64(len(g.units[g.currUnit.Template].Members))
 
-	points := size + 100.0*(1.0+ls)*ls/2.0
+	points = size + 100.0*(1.0+ls)*ls/2.0
 
 	var lineBonus int
 	if lsOld > 1 {
 		lineBonus = int((lsOld - 1.0) * points / 10.0)
 	}
 
-	moveScore := points + float64(lineBonus)
+	return points, float64(lineBonus)
+}
 
-	g.moveScore += moveScore
-	g.previousLinesCleared = linesCleared
+// ComboScore returns the part of the score earned by line bonuses, for
+// clearing lines after a lock which cleared several.
+func (g *Game) ComboScore() float64 {
+	return g.comboScore
+}
+
+// LockScore returns the move score for locking the current unit at p,
+// without locking it.
+func (g *Game) LockScore(p UnitPosition) float64 {
+	cells := g.Cells(p)
+
+	rows := make(map[int]int)
+	for _, c := range cells {
+		rows[c.Y]++
+	}
+
+	lines := 0
+	for y, n := range rows {
+		for x := 0; x < g.B.Width; x++ {
+			if g.B.Cells[x][y].Filled {
+				n++
+			}
+		}
+		if n == g.B.Width {
+			lines++
+		}
+	}
+
+	return g.movePoints(lines)
 }
 
 // Count occurrences of sep within s, allowing for overlap, which the spec
@@ -197,7 +274,7 @@ func (g *Game) updatePowerCount() (score int) {
 			continue
 		}
 
-		end := s[len(s) - len(p):]
+		end := s[len(s)-len(p):]
 		if end == p {
 			c, ok := g.powerWordCount[p]
 			if !ok {
@@ -248,6 +325,16 @@ func (g *Game) FinalScore() float64 {
 	return g.moveScore + float64(g.PowerFinalScore())
 }
 
+// powerWeight is how much the AIs value a power point against a move point
+// when they search. It is set for the whole run, by -power_weight.
+var powerWeight = 1.0
+
+// Objective returns the score the AIs search for, with the power score
+// weighted by powerWeight.
+func (g *Game) Objective() float64 {
+	return g.moveScore + powerWeight*float64(g.PowerScore())
+}
+
 // Rewrite commands with "final" power Phrases instead of normalized ones
 func (g *Game) WriteFinalCommands() {
 	s := g.Commands.String() // Copy starting commands
@@ -280,32 +367,24 @@ func (g *Game) Update(c Command) (bool, bool, error) {
 		return false, false, nil
 	}
 
-	var moved *Unit
-	isRot := d == CCW || d == CW
-	if isRot {
-		moved = g.currUnit.Rotate(d == CCW)
-	} else {
-		moved = g.currUnit.Translate(d)
-	}
+	moved := g.Move(g.currUnit, d)
 
-	// We cannot move into the same position, so we must add the current
-	// position (before the translate which we are about to check) to the
-	// list of moves to check against. We do this now, rather than the end
-	// of the previous Update call to prevent a bad rotation on the first
-	// move.
-	previousMoves := append(g.previousMoves, g.currUnit.DeepCopy())
-	if moved.OverlapsAny(previousMoves) {
+	// We cannot move into the same position, which includes the current
+	// position (before the move which we are about to check). It is only
+	// added to the list of previous moves once the move succeeds, to
+	// prevent a bad rotation on the first move.
+	if g.Visited(moved) {
 		return false, true, fmt.Errorf("moved unit from %+v to %+v and it overlaps with a previous move!", g.currUnit, moved)
 	}
 
 	// No more error beyond this point, record the command and previous
 	// moves.
 	g.Commands = append(g.Commands, c)
-	g.previousMoves = previousMoves
+	g.previousMoves = append(g.previousMoves, g.Key(g.currUnit))
 
 	g.updatePowerCount()
 
-	if g.B.IsValid(moved) {
+	if g.Fits(moved) {
 		g.currUnit = moved
 		return false, false, nil
 	}
@@ -323,6 +402,7 @@ func (g *Game) Update(c Command) (bool, bool, error) {
 
 	if ok := g.placeUnit(nextUnit); !ok {
 		// Game is done.
+		g.spawnFailed = true
 		return true, true, nil
 	}
 
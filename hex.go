@@ -0,0 +1,194 @@
+package main
+
+import (
+	"math"
+)
+
+// Hex grid geometry, in cube coordinates where possible. See
+// http://www.redblobgames.com/grids/hexagons for the details.
+
+var (
+	// cubeDirections are the unit vectors toward each neighbor.
+	cubeDirections = map[Direction]CubeVector{
+		E:  CubeVector{1, 0, -1},
+		NE: CubeVector{1, -1, 0},
+		NW: CubeVector{0, -1, 1},
+		W:  CubeVector{-1, 0, 1},
+		SW: CubeVector{-1, 1, 0},
+		SE: CubeVector{0, 1, -1},
+	}
+
+	// hexDirections lists the neighbor directions in counterclockwise
+	// order, starting from E.
+	hexDirections = []Direction{E, NE, NW, W, SW, SE}
+)
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max3(a, b, c int) int {
+	m := a
+	if b > m {
+		m = b
+	}
+	if c > m {
+		m = c
+	}
+	return m
+}
+
+func (v CubeVector) Add(o CubeVector) CubeVector {
+	return CubeVector{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+func (v CubeVector) Scale(k int) CubeVector {
+	return CubeVector{v.X * k, v.Y * k, v.Z * k}
+}
+
+// Length is the number of steps needed to move along v.
+func (v CubeVector) Length() int {
+	return max3(abs(v.X), abs(v.Y), abs(v.Z))
+}
+
+// ReflectX reflects v across the X axis, leaving X alone.
+func (v CubeVector) ReflectX() CubeVector {
+	return CubeVector{v.X, v.Z, v.Y}
+}
+
+// ReflectY reflects v across the Y axis, leaving Y (the row) alone. This is
+// a left-right mirror.
+func (v CubeVector) ReflectY() CubeVector {
+	return CubeVector{v.Z, v.Y, v.X}
+}
+
+// ReflectZ reflects v across the Z axis, leaving Z alone.
+func (v CubeVector) ReflectZ() CubeVector {
+	return CubeVector{v.Y, v.X, v.Z}
+}
+
+// Distance is the number of steps between c and o.
+func (c CubeCell) Distance(o CubeCell) int {
+	return c.VectorFrom(o).Length()
+}
+
+// Distance is the number of steps between c and o.
+func (c Cell) Distance(o Cell) int {
+	return c.ToCube().Distance(o.ToCube())
+}
+
+// Neighbor returns the cell next to c in direction d.
+func (c CubeCell) Neighbor(d Direction) CubeCell {
+	v, ok := cubeDirections[d]
+	if !ok {
+		panic("Cannot find neighbor in direction: " + d.String())
+	}
+	return c.Add(v)
+}
+
+// Neighbors returns the six cells around c, in hexDirections order.
+func (c Cell) Neighbors() []Cell {
+	cc := c.ToCube()
+	n := make([]Cell, len(hexDirections))
+	for i, d := range hexDirections {
+		n[i] = cc.Neighbor(d).ToCell()
+	}
+	return n
+}
+
+// Ring returns the cells exactly radius steps from c, walking
+// counterclockwise. A ring of radius 0 is just c.
+func (c CubeCell) Ring(radius int) []CubeCell {
+	if radius == 0 {
+		return []CubeCell{c}
+	}
+
+	ring := make([]CubeCell, 0, 6*radius)
+
+	// Start radius steps SW, so walking each direction in turn goes
+	// counterclockwise around c.
+	h := c.Add(cubeDirections[SW].Scale(radius))
+	for _, d := range hexDirections {
+		for i := 0; i < radius; i++ {
+			ring = append(ring, h)
+			h = h.Neighbor(d)
+		}
+	}
+
+	return ring
+}
+
+// Ring returns the cells exactly radius steps from c.
+func (c Cell) Ring(radius int) []Cell {
+	return cubeToCells(c.ToCube().Ring(radius))
+}
+
+// Within returns the cells at most radius steps from c, nearest first.
+func (c Cell) Within(radius int) []Cell {
+	var cells []Cell
+	for r := 0; r <= radius; r++ {
+		cells = append(cells, c.Ring(r)...)
+	}
+	return cells
+}
+
+// cubeRound rounds fractional cube coordinates to the nearest cell.
+func cubeRound(x, y, z float64) CubeCell {
+	rx, ry, rz := math.Floor(x+0.5), math.Floor(y+0.5), math.Floor(z+0.5)
+	dx, dy, dz := math.Abs(rx-x), math.Abs(ry-y), math.Abs(rz-z)
+
+	// Fix up the coordinate which was rounded the most so they still
+	// add up to zero.
+	if dx > dy && dx > dz {
+		rx = -ry - rz
+	} else if dy > dz {
+		ry = -rx - rz
+	} else {
+		rz = -rx - ry
+	}
+
+	return CubeCell{int(rx), int(ry), int(rz)}
+}
+
+// LineTo returns the cells on a straight line from c to o, inclusive.
+func (c CubeCell) LineTo(o CubeCell) []CubeCell {
+	n := c.Distance(o)
+	line := make([]CubeCell, n+1)
+
+	// Nudge off cell edges so ties always break the same way.
+	const eps = 1e-6
+	ax, ay, az := float64(c.X)+eps, float64(c.Y)+2*eps, float64(c.Z)-3*eps
+	bx, by, bz := float64(o.X)+eps, float64(o.Y)+2*eps, float64(o.Z)-3*eps
+
+	for i := 0; i <= n; i++ {
+		t := 0.0
+		if n > 0 {
+			t = float64(i) / float64(n)
+		}
+		line[i] = cubeRound(ax+(bx-ax)*t, ay+(by-ay)*t, az+(bz-az)*t)
+	}
+
+	return line
+}
+
+// LineTo returns the cells on a straight line from c to o, inclusive.
+func (c Cell) LineTo(o Cell) []Cell {
+	return cubeToCells(c.ToCube().LineTo(o.ToCube()))
+}
+
+// Reflect mirrors c left-right about the vertical line through pivot.
+func (c Cell) Reflect(pivot Cell) Cell {
+	p := pivot.ToCube()
+	return p.Add(c.ToCube().VectorFrom(p).ReflectY()).ToCell()
+}
+
+func cubeToCells(ccs []CubeCell) []Cell {
+	cells := make([]Cell, len(ccs))
+	for i, cc := range ccs {
+		cells[i] = cc.ToCell()
+	}
+	return cells
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCubeRoundTrip(t *testing.T) {
+	for x := -5; x <= 5; x++ {
+		for y := -5; y <= 5; y++ {
+			c := Cell{x, y}
+			cc := c.ToCube()
+			if cc.X+cc.Y+cc.Z != 0 {
+				t.Errorf("%+v.ToCube() = %+v, off the x+y+z=0 plane", c, cc)
+			}
+			if got := cc.ToCell(); got != c {
+				t.Errorf("%+v.ToCube().ToCell() got %+v", c, got)
+			}
+		}
+	}
+}
+
+func TestNeighborsMatchTranslate(t *testing.T) {
+	for x := -3; x <= 3; x++ {
+		for y := -3; y <= 3; y++ {
+			c := Cell{x, y}
+			n := c.Neighbors()
+			for i, d := range hexDirections {
+				if want := c.Translate(d); n[i] != want {
+					t.Errorf("%+v neighbor %s got %+v want %+v", c, d, n[i], want)
+				}
+				if dist := c.Distance(n[i]); dist != 1 {
+					t.Errorf("%+v.Distance(%+v) got %d want 1", c, n[i], dist)
+				}
+			}
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	center := Cell{2, -3}
+	for r := 0; r < 5; r++ {
+		ring := center.Ring(r)
+
+		want := 6 * r
+		if r == 0 {
+			want = 1
+		}
+		if len(ring) != want {
+			t.Errorf("Ring(%d) has %d cells want %d", r, len(ring), want)
+		}
+
+		for i, c := range ring {
+			if d := center.Distance(c); d != r {
+				t.Errorf("Ring(%d) cell %+v at distance %d", r, c, d)
+			}
+			if r > 0 {
+				next := ring[(i+1)%len(ring)]
+				if d := c.Distance(next); d != 1 {
+					t.Errorf("Ring(%d) cells %+v and %+v not adjacent", r, c, next)
+				}
+			}
+		}
+	}
+}
+
+func TestLineTo(t *testing.T) {
+	cases := []struct{ a, b Cell }{
+		{Cell{0, 0}, Cell{0, 0}},
+		{Cell{0, 0}, Cell{5, 0}},
+		{Cell{1, -4}, Cell{-3, 5}},
+		{Cell{3, 3}, Cell{0, 9}},
+	}
+
+	for _, c := range cases {
+		line := c.a.LineTo(c.b)
+		if len(line) != c.a.Distance(c.b)+1 {
+			t.Errorf("%+v.LineTo(%+v) has %d cells want %d", c.a, c.b, len(line), c.a.Distance(c.b)+1)
+		}
+		if line[0] != c.a || line[len(line)-1] != c.b {
+			t.Errorf("%+v.LineTo(%+v) = %v, wrong ends", c.a, c.b, line)
+		}
+		for i := 1; i < len(line); i++ {
+			if d := line[i-1].Distance(line[i]); d != 1 {
+				t.Errorf("%+v.LineTo(%+v) = %v, steps %d apart", c.a, c.b, line, d)
+			}
+		}
+	}
+}
+
+func TestReflect(t *testing.T) {
+	pivot := Cell{2, 1}
+	for _, c := range pivot.Within(3) {
+		r := c.Reflect(pivot)
+		if r.Y != c.Y {
+			t.Errorf("%+v.Reflect(%+v) = %+v changed row", c, pivot, r)
+		}
+		if r.Reflect(pivot) != c {
+			t.Errorf("%+v reflected twice got %+v", c, r.Reflect(pivot))
+		}
+		if pivot.Distance(r) != pivot.Distance(c) {
+			t.Errorf("%+v.Reflect(%+v) = %+v changed distance", c, pivot, r)
+		}
+	}
+
+	v := CubeVector{2, -3, 1}
+	for _, r := range []CubeVector{v.ReflectX(), v.ReflectY(), v.ReflectZ()} {
+		if r.Length() != v.Length() || r.X+r.Y+r.Z != 0 {
+			t.Errorf("reflection %+v of %+v is not a reflection", r, v)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+)
+
+// The inputs of a learned model are the Features of a game, followed by the
+// number of units left to place.
+const (
+	inputUnitsLeft = numFeatures + iota
+	numInputs
+)
+
+// learnedModel is loaded from -model, for the learned evaluator.
+var learnedModel *LinearModel
+
+func inputName(i int) string {
+	if i == inputUnitsLeft {
+		return "unitsLeft"
+	}
+	return featureNames[i]
+}
+
+// A sample is a position from self-play, with the final score its game
+// would be submitted with.
+type sample struct {
+	Features  Features `json:"features"`
+	UnitsLeft int      `json:"unitsLeft"`
+	Final     float64  `json:"final"`
+}
+
+func (s *sample) inputs() []float64 {
+	in := make([]float64, numInputs)
+	copy(in, s.Features[:])
+	in[inputUnitsLeft] = float64(s.UnitsLeft)
+	return in
+}
+
+// selfPlay plays g to the end with the AI called ai, returning a sample for
+// the start of each unit.
+func selfPlay(g *Game, ai string) []sample {
+	a := NewAI(g, ai, "")
+
+	var samples []sample
+	sent := 0
+	for {
+		if cur := a.Game(); cur.unitsSent != sent {
+			sent = cur.unitsSent
+			samples = append(samples, sample{Features: cur.Features(), UnitsLeft: cur.unitsLeft()})
+		}
+
+		done, err := a.Next()
+		if done || err != nil {
+			break
+		}
+	}
+
+	// The score of the commands as they would be submitted, as main
+	// reports it.
+	end := a.Game().Fork()
+	end.WriteFinalCommands()
+	final := end.FinalScore()
+	for i := range samples {
+		samples[i].Final = final
+	}
+	return samples
+}
+
+// runSelfPlay plays -selfplay_ai on the games from -f, or every qualifier
+// problem, writing the samples to -samples, one JSON object per line.
+func runSelfPlay() error {
+	if *samplesFile == "" {
+		return fmt.Errorf("no -samples file to save to")
+	}
+	if _, ok := ais[*selfPlayAI]; !ok {
+		return fmt.Errorf("invalid AI %q", *selfPlayAI)
+	}
+
+	games, err := loadGames(inputFiles, 0)
+	if err != nil {
+		return err
+	}
+
+	played := make([][]sample, len(games))
+	parallel(identityOrder(len(games)), func(i int) {
+		played[i] = selfPlay(games[i], *selfPlayAI)
+	})
+
+	f, err := os.Create(*samplesFile)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	e := json.NewEncoder(w)
+	n := 0
+	for _, ss := range played {
+		for i := range ss {
+			if err := e.Encode(&ss[i]); err != nil {
+				f.Close()
+				return err
+			}
+			n++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d samples from %d games to %s\n", n, len(games), *samplesFile)
+	return f.Close()
+}
+
+// readSamples reads samples written by runSelfPlay.
+func readSamples(name string) ([]sample, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var samples []sample
+	d := json.NewDecoder(f)
+	for d.More() {
+		var s sample
+		if err := d.Decode(&s); err != nil {
+			return nil, fmt.Errorf("could not parse samples in %s: %v", name, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// LinearModel estimates the final score of a game as a weighted sum of its
+// inputs.
+type LinearModel struct {
+	Bias    float64
+	Weights [numInputs]float64
+}
+
+// Predict returns the estimated final score of g.
+func (m *LinearModel) Predict(g *Game) float64 {
+	s := sample{Features: g.Features(), UnitsLeft: g.unitsLeft()}
+	return m.predict(s.inputs())
+}
+
+func (m *LinearModel) predict(in []float64) float64 {
+	s := m.Bias
+	for i, v := range in {
+		s += m.Weights[i] * v
+	}
+	return s
+}
+
+// linearModelJSON is how a LinearModel is saved, with the weights keyed by
+// input name.
+type linearModelJSON struct {
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+func loadModel(name string) (*LinearModel, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var j linearModelJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, fmt.Errorf("could not parse model in %s: %v", name, err)
+	}
+
+	m := &LinearModel{Bias: j.Bias}
+	for k, v := range j.Weights {
+		found := false
+		for i := 0; i < numInputs; i++ {
+			if inputName(i) == k {
+				m.Weights[i], found = v, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown input %q in %s", k, name)
+		}
+	}
+	return m, nil
+}
+
+func saveModel(name string, m *LinearModel) error {
+	j := linearModelJSON{Bias: m.Bias, Weights: make(map[string]float64)}
+	for i, w := range m.Weights {
+		j.Weights[inputName(i)] = w
+	}
+
+	b, err := json.MarshalIndent(&j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, append(b, '\n'), 0644)
+}
+
+// solve solves a x = b by Gaussian elimination with partial pivoting,
+// overwriting a and b.
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for c := 0; c < n; c++ {
+		p := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[p][c]) {
+				p = r
+			}
+		}
+		if a[p][c] == 0 {
+			return nil, fmt.Errorf("singular matrix")
+		}
+		a[c], a[p] = a[p], a[c]
+		b[c], b[p] = b[p], b[c]
+
+		for r := c + 1; r < n; r++ {
+			k := a[r][c] / a[c][c]
+			for j := c; j < n; j++ {
+				a[r][j] -= k * a[c][j]
+			}
+			b[r] -= k * b[c]
+		}
+	}
+
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		s := b[r]
+		for j := r + 1; j < n; j++ {
+			s -= a[r][j] * x[j]
+		}
+		x[r] = s / a[r][r]
+	}
+	return x, nil
+}
+
+// fitModel fits a LinearModel to samples by ridge regression, penalizing
+// the weights of the standardized inputs by lambda. Inputs which never
+// change get no weight.
+func fitModel(samples []sample, lambda float64) (*LinearModel, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+	n := float64(len(samples))
+
+	var mean, std [numInputs]float64
+	yMean := 0.0
+	for i := range samples {
+		for j, v := range samples[i].inputs() {
+			mean[j] += v
+		}
+		yMean += samples[i].Final
+	}
+	yMean /= n
+	for j := range mean {
+		mean[j] /= n
+	}
+
+	for i := range samples {
+		for j, v := range samples[i].inputs() {
+			std[j] += (v - mean[j]) * (v - mean[j])
+		}
+	}
+	for j := range std {
+		std[j] = math.Sqrt(std[j] / n)
+		if std[j] < 1e-9 {
+			// Only rounding error.
+			std[j] = 0
+		}
+	}
+
+	// The normal equations, (Z'Z + lambda I) beta = Z'y, over the
+	// standardized inputs Z.
+	a := make([][]float64, numInputs)
+	for j := range a {
+		a[j] = make([]float64, numInputs)
+	}
+	b := make([]float64, numInputs)
+	for i := range samples {
+		var z [numInputs]float64
+		for j, v := range samples[i].inputs() {
+			if std[j] > 0 {
+				z[j] = (v - mean[j]) / std[j]
+			}
+		}
+		for j := range z {
+			for k := range z {
+				a[j][k] += z[j] * z[k]
+			}
+			b[j] += z[j] * (samples[i].Final - yMean)
+		}
+	}
+	for j := range a {
+		a[j][j] += lambda
+		if std[j] == 0 {
+			// Keep the system solvable.
+			a[j][j] = 1
+		}
+	}
+
+	beta, err := solve(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &LinearModel{Bias: yMean}
+	for j := range beta {
+		if std[j] > 0 {
+			m.Weights[j] = beta[j] / std[j]
+			m.Bias -= m.Weights[j] * mean[j]
+		}
+	}
+	return m, nil
+}
+
+// runFit fits a model to the samples in -samples, and saves it to -model.
+func runFit() error {
+	if *samplesFile == "" || *modelFile == "" {
+		return fmt.Errorf("need -samples to read and -model to save to")
+	}
+
+	samples, err := readSamples(*samplesFile)
+	if err != nil {
+		return err
+	}
+
+	m, err := fitModel(samples, *ridge)
+	if err != nil {
+		return err
+	}
+
+	sq := 0.0
+	for i := range samples {
+		d := m.predict(samples[i].inputs()) - samples[i].Final
+		sq += d * d
+	}
+	fmt.Fprintf(os.Stderr, "Fit %d samples, RMS error %f\n", len(samples), math.Sqrt(sq/float64(len(samples))))
+
+	return saveModel(*modelFile, m)
+}
+
+// LearnedEvaluator scores games by the final score its Model expects them
+// to reach.
+type LearnedEvaluator struct {
+	Model *LinearModel
+}
+
+func newLearnedEvaluator() (Evaluator, error) {
+	if learnedModel == nil {
+		return nil, fmt.Errorf("no -model loaded for the learned evaluator")
+	}
+	return &LearnedEvaluator{Model: learnedModel}, nil
+}
+
+func (e *LearnedEvaluator) Evaluate(g *Game, lock *UnitPosition) float64 {
+	return e.Model.Predict(g)
+}
+
+func (e *LearnedEvaluator) Terms(g *Game, lock *UnitPosition) map[string]float64 {
+	s := sample{Features: g.Features(), UnitsLeft: g.unitsLeft()}
+	t := map[string]float64{"bias": e.Model.Bias}
+	for i, v := range s.inputs() {
+		if v := e.Model.Weights[i] * v; v != 0 {
+			t[inputName(i)] = v
+		}
+	}
+	return t
+}
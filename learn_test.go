@@ -0,0 +1,167 @@
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	// Needs a pivot: the first column starts with 0.
+	a := [][]float64{{0, 2, 1}, {1, 1, 0}, {2, 0, 3}}
+	b := []float64{7, 3, 11}
+	x, err := solve(a, b)
+	if err != nil {
+		t.Fatalf("solve err %v", err)
+	}
+	for i, want := range []float64{1, 2, 3} {
+		if math.Abs(x[i]-want) > 1e-9 {
+			t.Errorf("x[%d] got %v want %v", i, x[i], want)
+		}
+	}
+
+	if _, err := solve([][]float64{{1, 2}, {2, 4}}, []float64{1, 2}); err == nil {
+		t.Errorf("singular solve got no error")
+	}
+}
+
+func TestFitModel(t *testing.T) {
+	// final = 100 + 2 score - 30 holes + 5 unitsLeft, with the other
+	// features constant.
+	var samples []sample
+	for i := 0; i < 50; i++ {
+		s := sample{UnitsLeft: i % 7}
+		s.Features[featScore] = float64(i * 10)
+		s.Features[featHoles] = float64(i % 5)
+		s.Features[featHeight] = 3
+		s.Final = 100 + 2*s.Features[featScore] - 30*s.Features[featHoles] + 5*float64(s.UnitsLeft)
+		samples = append(samples, s)
+	}
+
+	m, err := fitModel(samples, 0)
+	if err != nil {
+		t.Fatalf("fitModel err %v", err)
+	}
+
+	want := LinearModel{Bias: 100}
+	want.Weights[featScore] = 2
+	want.Weights[featHoles] = -30
+	want.Weights[inputUnitsLeft] = 5
+	if math.Abs(m.Bias-want.Bias) > 1e-6 {
+		t.Errorf("bias got %v want %v", m.Bias, want.Bias)
+	}
+	for i := range m.Weights {
+		if math.Abs(m.Weights[i]-want.Weights[i]) > 1e-6 {
+			t.Errorf("%s weight got %v want %v", inputName(i), m.Weights[i], want.Weights[i])
+		}
+	}
+
+	// A ridge penalty shrinks the weights.
+	r, err := fitModel(samples, 1000)
+	if err != nil {
+		t.Fatalf("fitModel err %v", err)
+	}
+	if math.Abs(r.Weights[featHoles]) >= math.Abs(m.Weights[featHoles]) {
+		t.Errorf("ridge holes weight %v not below %v", r.Weights[featHoles], m.Weights[featHoles])
+	}
+}
+
+func TestModelFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "m.json")
+	m := &LinearModel{Bias: 7}
+	m.Weights[featHoles] = -3
+	m.Weights[inputUnitsLeft] = 12
+	if err := saveModel(name, m); err != nil {
+		t.Fatalf("saveModel err %v", err)
+	}
+	if got, err := loadModel(name); err != nil || *got != *m {
+		t.Errorf("loadModel got %v, %v want %v", got, err, m)
+	}
+}
+
+func TestSelfPlay(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+	samples := selfPlay(g.Fork(), "beamai")
+	if len(samples) != g.numUnits {
+		t.Fatalf("got %d samples want one per unit, %d", len(samples), g.numUnits)
+	}
+
+	for i, s := range samples {
+		if s.UnitsLeft != g.numUnits-i {
+			t.Errorf("sample %d has %d units left, want %d", i, s.UnitsLeft, g.numUnits-i)
+		}
+		if s.Final != samples[0].Final {
+			t.Errorf("sample %d final %v, first final %v", i, s.Final, samples[0].Final)
+		}
+	}
+
+	// The label is the final score, as main reports it, which can be less
+	// than the score so far of a sample, as that counts the normalized
+	// phrases.
+	a := NewAI(g.Fork(), "beamai", "")
+	for {
+		if done, err := a.Next(); done || err != nil {
+			break
+		}
+	}
+	a.Game().WriteFinalCommands()
+	if got, want := samples[0].Final, a.Game().FinalScore(); got != want {
+		t.Errorf("samples final %v, want the final score %v", got, want)
+	}
+}
+
+func TestLearnedEvaluator(t *testing.T) {
+	defer func(m *LinearModel) { learnedModel = m }(learnedModel)
+
+	learnedModel = nil
+	if _, err := NewEvaluator("learned"); err == nil {
+		t.Errorf("learned evaluator without a model got no error")
+	}
+
+	learnedModel = &LinearModel{Bias: 10}
+	learnedModel.Weights[inputUnitsLeft] = 100
+
+	g := GamesFromProblem(planProblem())[0]
+	e, err := NewEvaluator("learned")
+	if err != nil {
+		t.Fatalf("NewEvaluator err %v", err)
+	}
+	if got, want := e.Evaluate(g, nil), 10+100*float64(g.unitsLeft()); got != want {
+		t.Errorf("Evaluate got %v want %v", got, want)
+	}
+}
+
+func TestReadOldSamples(t *testing.T) {
+	dir, err := ioutil.TempDir("", "samples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Written before the combo features were added.
+	name := filepath.Join(dir, "samples.jsonl")
+	old := `{"features":[10,1,2,3,4,5,6],"unitsLeft":3,"final":50}` + "\n"
+	if err := ioutil.WriteFile(name, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := readSamples(name)
+	if err != nil || len(samples) != 1 {
+		t.Fatalf("readSamples got %v, %v", samples, err)
+	}
+	f := samples[0].Features
+	if f[featScore] != 10 || f[featHoles] != 1 || f[featStarved] != 6 || f[featCombo] != 0 || f[featReady] != 0 {
+		t.Errorf("old sample read as %v", f)
+	}
+}
@@ -39,6 +39,7 @@ func (m *MovePermuter) Next() (Command, error) {
 // LookaheadAI is really bad (and implements AI)
 type LookaheadAI struct {
 	game *Game
+	explained
 }
 
 // NewLookaheadAI builds a new LookaheadAI.
@@ -74,7 +75,7 @@ func (a *LookaheadAI) Next() (bool, error) {
 		ch <- aiResult{
 			command: c,
 			game:    g,
-			score:   g.Score(),
+			score:   g.Objective(),
 			done:    done,
 			err:     err,
 		}
@@ -126,6 +127,31 @@ func (a *LookaheadAI) Next() (bool, error) {
 
 	log.Printf("best: %+v", best)
 
+	a.last = explainLookahead(best, ret)
 	a.game = best.game
 	return best.done, best.err
 }
+
+// explainLookahead explains choosing best out of results, over the best of
+// the rest which did not fail, ranked as Next ranks them.
+func explainLookahead(best aiResult, results []aiResult) *Explanation {
+	choice := func(r aiResult) Choice {
+		return Choice{Move: r.command.String(), Score: r.score, Terms: scoreTerms(r.game)}
+	}
+
+	e := &Explanation{Chosen: choice(best)}
+	var next *aiResult
+	for i, r := range results {
+		if r.err != nil || r.command == best.command {
+			continue
+		}
+		if next == nil || (next.done && !r.done) || (next.done == r.done && r.score > next.score) {
+			next = &results[i]
+		}
+	}
+	if next != nil {
+		c := choice(*next)
+		e.RunnerUp = &c
+	}
+	return e
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime/pprof"
+	"strconv"
 	"time"
 )
 
@@ -32,6 +33,7 @@ var (
 	// These are registered in init(), below.
 	inputFiles   multiStringValue
 	powerPhrases multiStringValue
+	powerWeights multiFloatValue
 	aiFlags      multiStringValue = []string{"mcai", "cmc", "chanterai", "treeai"}
 	//aiFlags multiStringValue = []string{}
 
@@ -53,6 +55,34 @@ var (
 
 	profile = flag.String("profile", "", "Output CPU profile to file")
 
+	traceFile = flag.String("trace", "", "Write why the AIs made each decision to this file, as JSON lines")
+
+	mctsIterations = flag.Int("mcts_iters", 200, "MCTS iterations per unit")
+	mctsMoveTime   = flag.Duration("mcts_time", 0, "MCTS search time per unit, instead of -mcts_iters")
+	mctsRollout    = flag.String("mcts_rollout", "random", "MCTS rollout policy (random or greedy)")
+
+	evalFlag    = flag.String("eval", "", fmt.Sprintf("Evaluator for the search AIs (one of %s), instead of each AI's own", evaluatorNames()))
+	weightsFile = flag.String("weights", "", "JSON file of weights for the weighted evaluator, written by tune")
+
+	tuneAI     = flag.String("tune_ai", "beamai", "AI to play when tuning weights")
+	tuneRounds = flag.Int("tune_rounds", 10, "Rounds of coordinate search when tuning weights")
+	tuneSeeds  = flag.Int("tune_seeds", 0, "Seeds to play from each problem when tuning weights (0 for all)")
+
+	modelFile   = flag.String("model", "", "JSON model file for the learned evaluator, written by fit")
+	samplesFile = flag.String("samples", "samples.jsonl", "Self-play samples file, written by selfplay and read by fit")
+	selfPlayAI  = flag.String("selfplay_ai", "beamai", "AI to play for selfplay samples")
+	ridge       = flag.Float64("ridge", 1, "Ridge penalty on the standardized weights when fitting a model")
+
+	lookahead = flag.Int("lookahead", 0, "Upcoming units, from the current one, which the weighted evaluator checks have room (0 to disable)")
+
+	moveTime = flag.Duration("movetime", 0, "Search time per move for the tree AIs, deepening as far as it allows (0 for fixed depth)")
+
+	annealTime = flag.Duration("anneal_time", time.Minute, "Time to spend annealing each game with annealai")
+	gaTime     = flag.Duration("ga_time", time.Minute, "Time to spend evolving each game with gaai")
+
+	endgameUnits = flag.Int("endgame", 0, "Search every placement of the units left once there are this many (0 to disable)")
+	burn         = flag.Bool("burn", false, "Spend the last unit on phrases of power")
+
 	repeat    = flag.String("repeat", "", "String for RepeaterAI to run")
 	seed      = flag.Uint64("seed", 0xFFFFFFFFFFFFFFFF, "Use specific seed for single game")
 	customtag = flag.String("customtag", "", "Custom tag for solution")
@@ -75,6 +105,25 @@ func (s *multiStringValue) Set(v string) error {
 	return nil
 }
 
+// multiFloatValue is a flag.Value for numbers which can be specified
+// multiple times on the command line.
+type multiFloatValue []float64
+
+// String implements flag.Value.String.
+func (s *multiFloatValue) String() string {
+	return fmt.Sprintf("%v", *s)
+}
+
+// Set implements flag.Value.Set, adding each new number to the slice.
+func (s *multiFloatValue) Set(v string) error {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, f)
+	return nil
+}
+
 type devNull struct{}
 
 // Write consumes all.
@@ -87,6 +136,15 @@ func ArgsOk() error {
 		return nil
 	}
 
+	switch flag.Arg(0) {
+	case "":
+	case "tune", "selfplay", "fit":
+		// These play every qualifier by default, or none at all.
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", flag.Arg(0))
+	}
+
 	if len(inputFiles) == 0 {
 		return fmt.Errorf("no file names specified")
 	}
@@ -100,6 +158,32 @@ type AISolution struct {
 	score    float64
 }
 
+// portfolioRun is an AI to play each game with, and the powerWeight to
+// search with.
+type portfolioRun struct {
+	ai          string
+	powerWeight float64
+}
+
+// String names the run by its AI, and by its weight if there is a choice.
+func (r portfolioRun) String() string {
+	if len(powerWeights) > 1 {
+		return fmt.Sprintf("%s@%g", r.ai, r.powerWeight)
+	}
+	return r.ai
+}
+
+// portfolio returns the runs for every AI and power weight asked for.
+func portfolio() []portfolioRun {
+	var runs []portfolioRun
+	for _, ai := range aiFlags {
+		for _, w := range powerWeights {
+			runs = append(runs, portfolioRun{ai: ai, powerWeight: w})
+		}
+	}
+	return runs
+}
+
 func main() {
 	flag.Parse()
 
@@ -118,6 +202,12 @@ func main() {
 	}
 	normalizePhrases()
 
+	// The commands which play a single game search with the first.
+	if len(powerWeights) == 0 {
+		powerWeights = multiFloatValue{1}
+	}
+	powerWeight = powerWeights[0]
+
 	if *profile != "" {
 		f, err := os.Create(*profile)
 		if err != nil {
@@ -127,12 +217,61 @@ func main() {
 		pprof.StartCPUProfile(f)
 	}
 
+	if *weightsFile != "" {
+		w, err := loadWeights(*weightsFile)
+		if err == nil {
+			defaultWeights = w
+		} else if !(flag.Arg(0) == "tune" && os.IsNotExist(err)) {
+			// tune may be about to write the file.
+			log.Fatalf("Could not load weights: %v", err)
+		}
+	}
+
+	if *modelFile != "" && flag.Arg(0) != "fit" {
+		m, err := loadModel(*modelFile)
+		if err != nil {
+			log.Fatalf("Could not load model: %v", err)
+		}
+		learnedModel = m
+	}
+
+	if cmd := flag.Arg(0); cmd != "" {
+		var err error
+		switch cmd {
+		case "tune":
+			err = runTune()
+		case "selfplay":
+			err = runSelfPlay()
+		case "fit":
+			err = runFit()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+
+		if *profile != "" {
+			pprof.StopCPUProfile()
+		}
+		return
+	}
+
 	if *serve {
 		log.Printf("Running server...")
 		runServer()
 		return
 	}
 
+	var trace *tracer
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			log.Fatalf("Could not create trace file %s: %v", *traceFile, err)
+		}
+		defer f.Close()
+		trace = newTracer(f)
+	}
+
 	var timeout <-chan time.Time
 	timedOut := false
 	if *timeLimit > 0 {
@@ -147,6 +286,7 @@ func main() {
 		}
 
 		timeout = time.After(t * time.Second)
+		deadline = time.Now().Add(t * time.Second)
 	}
 
 	var output []OutputEntry
@@ -179,10 +319,12 @@ func main() {
 			}
 
 			var aiSolutions []AISolution
-			for _, ai := range aiFlags {
+			for _, run := range portfolio() {
 				if timedOut {
 					break
 				}
+				ai := run.ai
+				powerWeight = run.powerWeight
 
 				var renderer *GameRenderer
 				if *render {
@@ -192,7 +334,7 @@ func main() {
 				aiGame := g.Fork()
 
 				log.Printf("Playing %+v", aiGame)
-				log.Printf("Using AI: %s", ai)
+				log.Printf("Using AI: %s", run)
 				a := NewAI(aiGame, ai, *repeat)
 
 				i := 1
@@ -212,6 +354,13 @@ func main() {
 					}
 
 					done, err := a.Next()
+					if e := explain(a); e != nil {
+						log.Printf("Decided %v", e)
+						if err := trace.Trace(problem.Id, problem.SourceSeeds[gi], run.String(), a.Game().unitsSent, e); err != nil {
+							log.Fatalf("Failed to write trace: %v", err)
+						}
+					}
+
 					if done {
 						log.Println("Game done!")
 						break
@@ -242,7 +391,7 @@ func main() {
 				}
 
 				aiSolutions = append(aiSolutions, AISolution{
-					name:     ai,
+					name:     run.String(),
 					commands: a.Game().FinalCommands.String(),
 					score:    a.Game().FinalScore(),
 				})
@@ -285,6 +434,7 @@ func main() {
 func init() {
 	flag.Var(&inputFiles, "f", "File containing JSON encoded input.")
 	flag.Var(&powerPhrases, "p", "Phrase of power")
+	flag.Var(&powerWeights, "power_weight", "Weight of power points against move points when the AIs search (can be multiple, to try each and keep the best; default 1)")
 
 	var keys string
 	comma := ""
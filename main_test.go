@@ -68,3 +68,32 @@ func TestLCG(t *testing.T) {
 		}
 	}
 }
+
+func TestPortfolio(t *testing.T) {
+	defer func(a multiStringValue, w multiFloatValue) { aiFlags, powerWeights = a, w }(aiFlags, powerWeights)
+	aiFlags = multiStringValue{"treeai", "beamai"}
+
+	powerWeights = nil
+	for _, v := range []string{"0.5", "2"} {
+		if err := powerWeights.Set(v); err != nil {
+			t.Fatalf("Set(%q) err %v", v, err)
+		}
+	}
+	if err := powerWeights.Set("lots"); err == nil {
+		t.Errorf("Set(\"lots\") got %v, want an error", powerWeights)
+	}
+
+	var got []string
+	for _, r := range portfolio() {
+		got = append(got, r.String())
+	}
+	if want := "[treeai@0.5 treeai@2 beamai@0.5 beamai@2]"; fmt.Sprint(got) != want {
+		t.Errorf("portfolio() got %v want %v", got, want)
+	}
+
+	// A single weight leaves the names alone.
+	powerWeights = multiFloatValue{1}
+	if r := portfolio()[0]; r.String() != "treeai" || r.powerWeight != 1 {
+		t.Errorf("portfolio()[0] got %v with weight %v", r, r.powerWeight)
+	}
+}
@@ -12,6 +12,10 @@ type MCNode struct {
 	done   bool
 	err    error
 	score  float64
+
+	// Shared by the whole tree.
+	tt   *TranspositionTable
+	eval Evaluator
 }
 
 type weightedDir struct {
@@ -100,33 +104,55 @@ func drawDirs(n int, wds []weightedDir) []Direction {
 	return ds
 }
 
-func (n *MCNode) tryDirection(d Direction, scoresofar float64, tries int) (bool, float64) {
+// tryDirection probes moving d, then random moves after it, returning
+// whether the probe ended the game, and its value. A probe is worth what
+// the evaluator makes of the game it ends at, plus, for every unit locked
+// on the way, how much more the evaluator made of it just before it locked
+// than when it spawned, which is banked so far. spawned is what it made of
+// the current unit's spawn.
+func (n *MCNode) tryDirection(d Direction, banked, spawned float64, tries int) (bool, float64) {
 	//defer log.Printf("leaving! %+v\n", n)
 	//log.Printf("tries: %d try dir: %+v node %+v\n", tries, d, n)
 	thisUnit := n.g.currUnit
+	if !n.g.Fits(n.g.Orientations().Move(thisUnit, d)) {
+		banked += n.eval.Evaluate(n.g, nil) - spawned
+	}
+
 	locked, done, err := n.g.Update(directionToCommands[d][0])
 	n.done, n.err = done, err
 	if err != nil {
-		return true, scoresofar + n.g.Score() - 1000000.0
+		// A move which fails is as bad as dying.
+		return true, banked + n.g.Objective() - deathPenalty
 	}
 
 	if done {
-		return true, scoresofar + n.g.Score() - 1000000.0
-	}
-
-	// We must go deeper
-	if d == SW || d == SE {
-		scoresofar += 10.0
+		return true, banked + searchValue(n.eval, n.g, nil, done)
 	}
 
+	// Probes are valued by the games they reach, without the evaluator's
+	// terms for a lock, which would reward a probe for where it happened
+	// to stop.
 	if locked {
-		if n.g.B.GapBelowAny(thisUnit) {
-			return false, scoresofar + n.g.Score()
+		if n.g.B.GapBelowAny(n.g.Cells(thisUnit)) {
+			return false, banked + n.eval.Evaluate(n.g, nil)
 		}
+		spawned = n.eval.Evaluate(n.g, nil)
 	}
 
 	if tries == 0 {
-		return false, scoresofar + n.g.Score()
+		return false, banked + n.eval.Evaluate(n.g, nil)
+	}
+
+	// Probes from a position already probed at least as deeply are not
+	// worth repeating. What was banked depends on the path, so only what
+	// was scored beyond it is remembered.
+	var h uint64
+	if n.tt != nil {
+		h = n.g.Hash()
+	}
+	if e, ok := n.tt.Lookup(h); ok && e.Depth >= tries {
+		n.score = banked + e.Score
+		return false, n.score
 	}
 
 	tried := n.probed[int(d)]
@@ -138,10 +164,12 @@ func (n *MCNode) tryDirection(d Direction, scoresofar float64, tries int) (bool,
 			tried = &MCNode{
 				g:      n.g.Fork(),
 				probed: make([]*MCNode, int(NOP)+1),
+				tt:     n.tt,
+				eval:   n.eval,
 			}
 
 			dir := drawDir(currdirs)
-			ded, score = tried.tryDirection(dir, scoresofar, tries-1)
+			ded, score = tried.tryDirection(dir, banked, spawned, tries-1)
 			if !ded {
 				break
 			}
@@ -156,6 +184,7 @@ func (n *MCNode) tryDirection(d Direction, scoresofar float64, tries int) (bool,
 
 		n.probed[int(d)] = tried
 		n.score = score
+		n.tt.Store(TTEntry{Hash: h, Score: score - banked, Depth: tries, Move: int(d)})
 	} else {
 		score = tried.score
 	}
@@ -165,6 +194,8 @@ func (n *MCNode) tryDirection(d Direction, scoresofar float64, tries int) (bool,
 
 func (root *MCNode) tryDirections(n int, wds *[]weightedDir) (Direction, []Direction) {
 	ds := drawDirs(n, *wds)
+	// Moves of the current unit are credited from here.
+	spawned := root.eval.Evaluate(root.g, nil)
 	//log.Printf("drawn dirs: %+v\n", ds)
 	for _, d := range ds {
 		chld := root.probed[int(d)]
@@ -172,9 +203,11 @@ func (root *MCNode) tryDirections(n int, wds *[]weightedDir) (Direction, []Direc
 			chld = &MCNode{
 				g:      root.g.Fork(),
 				probed: make([]*MCNode, int(NOP)+1),
+				tt:     root.tt,
+				eval:   root.eval,
 			}
 
-			_, _ = chld.tryDirection(d, 0.0, probeDepth)
+			_, _ = chld.tryDirection(d, 0, spawned, probeDepth)
 			root.probed[int(d)] = chld
 		}
 	}
@@ -200,12 +233,17 @@ func (root *MCNode) tryDirections(n int, wds *[]weightedDir) (Direction, []Direc
 type MonteCarloid struct {
 	g    *Game
 	root *MCNode
+	explained
 }
 
 func NewMonteCarloid(g *Game, _ string) AI {
 	newroot := &MCNode{
 		g:      g,
 		probed: make([]*MCNode, int(NOP)+1),
+		tt:     NewTranspositionTable(ttSize),
+		// The legacy evaluator's row term credits probes for moving
+		// units down.
+		eval: LegacyEvaluator{},
 	}
 	return &MonteCarloid{g: g, root: newroot}
 }
@@ -214,6 +252,11 @@ func (m *MonteCarloid) Game() *Game {
 	return m.g
 }
 
+// SetEvaluator implements EvaluatorSetter.
+func (m *MonteCarloid) SetEvaluator(e Evaluator) {
+	m.root.eval = e
+}
+
 func (m *MonteCarloid) Next() (bool, error) {
 	var best *MCNode
 	var d Direction
@@ -246,8 +289,35 @@ func (m *MonteCarloid) Next() (bool, error) {
 		panic("ROOT IS NIL BUT YOU JUST WENT IN THAT DIRECTION")
 	}
 
+	m.last = m.root.explain(best)
 	m.root = best
 	m.g = m.root.g
 	//log.Printf("next done: %+v", m.root)
 	return m.root.done, m.root.err
 }
+
+// explain explains moving to the probed child best, over the best of the
+// other probed children which did not end the game.
+func (root *MCNode) explain(best *MCNode) *Explanation {
+	choice := func(d Direction, n *MCNode) Choice {
+		return Choice{Move: d.String(), Score: n.score, Terms: map[string]float64{"probe": n.score}}
+	}
+
+	e := &Explanation{}
+	var next *MCNode
+	var nextDir Direction
+	for d, c := range root.probed {
+		switch {
+		case c == nil:
+		case c == best:
+			e.Chosen = choice(Direction(d), c)
+		case !c.done && c.err == nil && (next == nil || c.score > next.score):
+			next, nextDir = c, Direction(d)
+		}
+	}
+	if next != nil {
+		c := choice(nextDir, next)
+		e.RunnerUp = &c
+	}
+	return e
+}
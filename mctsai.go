@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	// uctExplore is the UCB1 exploration constant, on rewards scaled to
+	// [0, 1].
+	uctExplore = math.Sqrt2
+
+	// mctsRolloutDepth is the number of units a rollout places before the
+	// game is evaluated.
+	mctsRolloutDepth = 2
+)
+
+// rolloutPolicies choose the next placement during a rollout, given every
+// placement from g, and the evaluator for the search.
+var rolloutPolicies = map[string]func(eval Evaluator, g *Game, ps []Placement) Placement{
+	"random": randomRollout,
+	"greedy": greedyRollout,
+}
+
+func randomRollout(eval Evaluator, g *Game, ps []Placement) Placement {
+	return ps[rand.Intn(len(ps))]
+}
+
+// greedyRollout picks the placement with the best immediate value.
+func greedyRollout(eval Evaluator, g *Game, ps []Placement) Placement {
+	best := ps[0]
+	bestValue := math.Inf(-1)
+	for _, p := range ps {
+		f := g.Fork()
+		_, done, err := f.Play(p.Commands)
+		if err != nil {
+			continue
+		}
+		if v := searchValue(eval, f, &p.Position, done); v > bestValue {
+			best, bestValue = p, v
+		}
+	}
+	return best
+}
+
+type mctsNode struct {
+	parent   *mctsNode
+	children []*mctsNode
+
+	// placement reached this node from its parent.
+	placement Placement
+	game      *Game
+	done      bool
+
+	// untried are the placements from here without a child yet, except
+	// those which cannot be best. It is nil until the node is first
+	// expanded.
+	untried  []Expansion
+	expanded bool
+
+	visits int
+	total  float64
+
+	// Rollouts which died, and the total of those which did not.
+	deaths    int
+	liveTotal float64
+}
+
+func newMCTSNode(parent *mctsNode, p Placement, g *Game, done bool) *mctsNode {
+	return &mctsNode{
+		parent:    parent,
+		placement: p,
+		game:      g,
+		done:      done,
+	}
+}
+
+// uct returns the UCB1 value of n, with rewards scaled to [0, 1]. A
+// rollout which died is worth 0, and one which did not is worth from a half
+// to 1, by where its reward falls between lo and hi, the range of live
+// rewards. Scaling by the death penalty instead would squash the live
+// rewards together.
+func (n *mctsNode) uct(lo, hi float64) float64 {
+	if n.visits == 0 {
+		return math.Inf(1)
+	}
+
+	mean := 0.0
+	if lives := n.visits - n.deaths; lives > 0 {
+		scaled := 0.5
+		if hi > lo {
+			scaled = (n.liveTotal/float64(lives) - lo) / (hi - lo)
+		}
+		mean = float64(lives) / float64(n.visits) * (0.5 + 0.5*scaled)
+	}
+
+	return mean + uctExplore*math.Sqrt(math.Log(float64(n.parent.visits))/float64(n.visits))
+}
+
+// expand adds one untried child, returning nil if there are none left.
+func (n *mctsNode) expand(eval Evaluator) *mctsNode {
+	if !n.expanded {
+		n.untried = n.game.Expand(eval)
+		n.expanded = true
+	}
+
+	if len(n.untried) == 0 {
+		return nil
+	}
+
+	i := rand.Intn(len(n.untried))
+	e := n.untried[i]
+	n.untried[i] = n.untried[len(n.untried)-1]
+	n.untried = n.untried[:len(n.untried)-1]
+
+	c := newMCTSNode(n, e.Placement, e.Game, e.Done)
+	n.children = append(n.children, c)
+	return c
+}
+
+// mostVisited returns the child searched the most, which is the move to
+// play.
+func (n *mctsNode) mostVisited() *mctsNode {
+	var best *mctsNode
+	for _, c := range n.children {
+		if best == nil || c.visits > best.visits || (c.visits == best.visits && c.total > best.total) {
+			best = c
+		}
+	}
+	return best
+}
+
+// MCTSAI is a UCT Monte Carlo tree search over placements. Every iteration
+// selects down the tree by UCB1, expands one new placement, rolls out
+// mctsRolloutDepth more units with the rollout policy, and backs the value
+// of the resulting game up the path. The subtree below the chosen placement
+// is kept for the next unit.
+type MCTSAI struct {
+	game    *Game
+	current Commands
+
+	root    *mctsNode
+	rollout func(eval Evaluator, g *Game, ps []Placement) Placement
+	eval    Evaluator
+	explained
+
+	// Range of rewards seen from rollouts which did not die, to scale
+	// them for UCB1.
+	lo, hi float64
+
+	iterations int
+	budget     time.Duration
+}
+
+func NewMCTSAI(g *Game, _ string) AI {
+	rollout, ok := rolloutPolicies[*mctsRollout]
+	if !ok {
+		panic(fmt.Sprintf("unknown rollout policy %q", *mctsRollout))
+	}
+
+	return &MCTSAI{
+		game:       g,
+		rollout:    rollout,
+		eval:       &WeightedEvaluator{Weights: defaultWeights},
+		lo:         math.Inf(1),
+		hi:         math.Inf(-1),
+		iterations: *mctsIterations,
+		budget:     *mctsMoveTime,
+	}
+}
+
+// Game returns the Game used by the AI.
+// It may change after calls to Next().
+func (a *MCTSAI) Game() *Game {
+	return a.game
+}
+
+// SetEvaluator implements EvaluatorSetter.
+func (a *MCTSAI) SetEvaluator(e Evaluator) {
+	a.eval = e
+}
+
+// simulate plays out from g, returning the value of the final game, and
+// whether it died.
+func (a *MCTSAI) simulate(g *Game, done bool) (float64, bool) {
+	if !done && mctsRolloutDepth > 0 {
+		g = g.Fork()
+		for i := 0; i < mctsRolloutDepth && !done; i++ {
+			ps := g.Placements()
+			if len(ps) == 0 {
+				break
+			}
+
+			var err error
+			_, done, err = g.Play(a.rollout(a.eval, g, ps).Commands)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	return searchValue(a.eval, g, nil, done), done && g.Died()
+}
+
+// iterate runs one selection, expansion, rollout and backpropagation.
+func (a *MCTSAI) iterate() {
+	n := a.root
+	for !n.done {
+		if c := n.expand(a.eval); c != nil {
+			n = c
+			break
+		}
+		if len(n.children) == 0 {
+			break
+		}
+
+		best := n.children[0]
+		bestValue := math.Inf(-1)
+		for _, c := range n.children {
+			if v := c.uct(a.lo, a.hi); v > bestValue {
+				best, bestValue = c, v
+			}
+		}
+		n = best
+	}
+
+	r, dead := a.simulate(n.game, n.done)
+	a.backup(n, r, dead)
+}
+
+// backup adds reward r, from a rollout which died or not, to n and the
+// nodes above it.
+func (a *MCTSAI) backup(n *mctsNode, r float64, dead bool) {
+	if !dead {
+		a.lo = math.Min(a.lo, r)
+		a.hi = math.Max(a.hi, r)
+	}
+
+	for ; n != nil; n = n.parent {
+		n.visits++
+		n.total += r
+		if dead {
+			n.deaths++
+		} else {
+			n.liveTotal += r
+		}
+	}
+}
+
+// search grows the tree within the per-move budget, which is an iteration
+// count, or a time limit if that is set.
+func (a *MCTSAI) search() {
+	if a.budget > 0 {
+		deadline := time.Now().Add(a.budget)
+		for time.Now().Before(deadline) {
+			a.iterate()
+		}
+		return
+	}
+
+	for i := 0; i < a.iterations; i++ {
+		a.iterate()
+	}
+}
+
+// reuse moves the root to the subtree for the current game, if it is in the
+// tree, or starts a new tree.
+//
+// The games in the tree were played along shortest paths, but the current
+// game along the PhrasePath, so they differ in power. A kept subtree is
+// played again from the current game, and its rewards moved by the
+// difference in the value of its root.
+func (a *MCTSAI) reuse() {
+	a.lo, a.hi = math.Inf(1), math.Inf(-1)
+
+	if a.root != nil {
+		for _, c := range a.root.children {
+			if c.game.B.hash == a.game.B.hash && c.game.currUnit == a.game.currUnit && c.game.unitsSent == a.game.unitsSent {
+				log.Printf("Reusing subtree with %d visits", c.visits)
+				shift := searchValue(a.eval, a.game, nil, c.done) - searchValue(a.eval, c.game, nil, c.done)
+				c.parent = nil
+				c.game = a.game
+				a.replay(c, shift)
+				a.root = c
+				return
+			}
+		}
+	}
+
+	a.root = newMCTSNode(nil, Placement{}, a.game, false)
+}
+
+// replay plays the children of n, and its untried placements, again from
+// the game of n, adds shift to the rewards of n and those below, and widens
+// lo and hi to hold their mean live rewards.
+func (a *MCTSAI) replay(n *mctsNode, shift float64) {
+	n.total += float64(n.visits) * shift
+	if lives := n.visits - n.deaths; lives > 0 {
+		n.liveTotal += float64(lives) * shift
+		mean := n.liveTotal / float64(lives)
+		a.lo = math.Min(a.lo, mean)
+		a.hi = math.Max(a.hi, mean)
+	}
+
+	for i := range n.untried {
+		e := &n.untried[i]
+		e.Game = n.game.Fork()
+		e.Game.Play(e.Commands)
+	}
+	for _, c := range n.children {
+		c.game = n.game.Fork()
+		c.game.Play(c.placement.Commands)
+		a.replay(c, shift)
+	}
+}
+
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (a *MCTSAI) Next() (bool, error) {
+	if len(a.current) == 0 {
+		a.reuse()
+		a.search()
+
+		if best := a.root.mostVisited(); best != nil {
+			a.last = a.explain(best)
+			a.current = a.game.PhrasePath(best.placement)
+			log.Printf("Placing at %+v after %d visits, mean %f: %s", best.placement.Position, best.visits, best.total/float64(best.visits), &a.current)
+		} else {
+			a.current = Commands{directionToCommands[SE][0]}
+		}
+	}
+
+	c := a.current[0]
+	a.current = a.current[1:]
+
+	locked, done, err := a.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	if locked {
+		a.current = nil
+	}
+	return done, err
+}
+
+// explain explains choosing best, the most visited placement from the root,
+// over the next most visited. The terms are the evaluation of the game each
+// placement leads to.
+func (a *MCTSAI) explain(best *mctsNode) *Explanation {
+	choice := func(n *mctsNode) Choice {
+		return Choice{
+			Move:   fmt.Sprintf("%+v", n.placement.Position),
+			Score:  n.total / float64(n.visits),
+			Terms:  searchTerms(a.eval, n.game, &n.placement.Position, n.done),
+			Visits: n.visits,
+		}
+	}
+
+	e := &Explanation{Chosen: choice(best)}
+	var next *mctsNode
+	for _, c := range a.root.children {
+		if c != best && c.visits > 0 && (next == nil || c.visits > next.visits || (c.visits == next.visits && c.total > next.total)) {
+			next = c
+		}
+	}
+	if next != nil {
+		c := choice(next)
+		e.RunnerUp = &c
+	}
+	return e
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMCTSAI(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        4,
+		Height:       6,
+		SourceLength: 6,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	a := NewMCTSAI(g, "").(*MCTSAI)
+	a.iterations = 50
+
+	var roots []*mctsNode
+	for i := 0; ; i++ {
+		if len(a.current) == 0 && a.root != nil {
+			roots = append(roots, a.root)
+		}
+
+		done, err := a.Next()
+		if err != nil {
+			t.Fatalf("Next() err %v", err)
+		}
+		if done {
+			break
+		}
+		if i > 1000 {
+			t.Fatalf("game not done after %d moves", i)
+		}
+	}
+
+	// Two units on each row clear it, so everything can be placed.
+	if a.game.unitsSent != p.SourceLength {
+		t.Errorf("game ended after %d units, want %d", a.game.unitsSent, p.SourceLength)
+	}
+
+	// The tree is reused, so the root always starts searched.
+	reused := 0
+	for _, r := range roots {
+		if r.visits > a.iterations {
+			reused++
+		}
+	}
+	if reused == 0 {
+		t.Errorf("tree never reused")
+	}
+}
+
+func TestMCTSReuse(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+	a := NewMCTSAI(g, "").(*MCTSAI)
+	a.iterations = 200
+
+	a.reuse()
+	a.search()
+	best := a.root.mostVisited()
+	if _, _, err := a.game.Play(a.game.PhrasePath(best.placement)); err != nil {
+		t.Fatalf("Play err %v", err)
+	}
+
+	a.reuse()
+	if a.root != best {
+		t.Fatalf("subtree not reused")
+	}
+	if a.root.game != a.game {
+		t.Errorf("reused root is not the game played")
+	}
+
+	// Everything below is played from the game as it really is.
+	var check func(n *mctsNode)
+	check = func(n *mctsNode) {
+		for _, e := range n.untried {
+			want := n.game.Fork()
+			want.Play(e.Commands)
+			if e.Game.Hash() != want.Hash() {
+				t.Errorf("untried %+v not played from its parent", e.Position)
+			}
+		}
+		for _, c := range n.children {
+			want := n.game.Fork()
+			want.Play(c.placement.Commands)
+			if c.game.Hash() != want.Hash() {
+				t.Errorf("child %+v not played from its parent", c.placement.Position)
+			}
+			check(c)
+		}
+	}
+	check(a.root)
+
+	// The range starts again from the kept means.
+	if len(a.root.children) > 0 && !(a.lo <= a.hi) {
+		t.Errorf("range [%v, %v] after reuse", a.lo, a.hi)
+	}
+}
+
+func TestMCTSBackup(t *testing.T) {
+	a := &MCTSAI{lo: math.Inf(1), hi: math.Inf(-1)}
+	root := newMCTSNode(nil, Placement{}, nil, false)
+	x := newMCTSNode(root, Placement{}, nil, false)
+	y := newMCTSNode(root, Placement{}, nil, false)
+
+	a.backup(x, 100, false)
+	a.backup(y, 200, false)
+	a.backup(x, 100-deathPenalty, true)
+	a.backup(y, 150, false)
+
+	if a.lo != 100 || a.hi != 200 {
+		t.Errorf("range [%v, %v], want [100, 200]", a.lo, a.hi)
+	}
+	if root.visits != 4 {
+		t.Errorf("root visits %v, want 4", root.visits)
+	}
+
+	// Both are visited as often. x lived once with the worst live reward,
+	// worth a half, and died once, and y lived twice with rewards averaging
+	// three quarters of the way up.
+	if xv, yv := x.uct(a.lo, a.hi), y.uct(a.lo, a.hi); math.Abs(yv-xv-(0.875-0.25)) > 1e-9 {
+		t.Errorf("uct x %v y %v, want y 0.625 ahead", xv, yv)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"sort"
+)
+
+// Orientations holds the six rotations of a unit template about its pivot.
+type Orientations struct {
+	// Offsets[r] are the vectors from the pivot to each member after r
+	// clockwise turns.
+	Offsets [6][]CubeVector
+
+	// Period is the smallest number of turns which maps the members back
+	// onto themselves. Rotations r and r+Period cover identical cells, so
+	// only the first Period rotations need to be searched.
+	Period int
+
+	// Shape[r] is the first rotation whose cells are a translation of
+	// those of rotation r. Rotations with the same Shape can lock in
+	// exactly the same places.
+	Shape [6]int
+
+	// Distinct is the number of different shapes, ignoring translation.
+	// A line has 3, a hex ring has 1.
+	Distinct int
+
+	// Anchor[r] is the offset of the least member of rotation r. Rotations
+	// with the same Shape cover the same cells when their anchors do.
+	Anchor [6]CubeVector
+}
+
+// NewOrientations precomputes the rotations of u.
+func NewOrientations(u *Unit) Orientations {
+	var o Orientations
+
+	p := u.Pivot.ToCube()
+	o.Offsets[0] = make([]CubeVector, len(u.Members))
+	for i, c := range u.Members {
+		o.Offsets[0][i] = c.ToCube().VectorFrom(p)
+	}
+
+	for r := 1; r < 6; r++ {
+		o.Offsets[r] = make([]CubeVector, len(u.Members))
+		for i, v := range o.Offsets[r-1] {
+			o.Offsets[r][i] = v.Rotate(false)
+		}
+	}
+
+	o.Period = 6
+	for r := 1; r < 6; r++ {
+		if sameVectors(o.Offsets[0], o.Offsets[r]) {
+			o.Period = r
+			break
+		}
+	}
+
+	for r := 0; r < 6; r++ {
+		if len(o.Offsets[r]) > 0 {
+			sorted := append([]CubeVector(nil), o.Offsets[r]...)
+			sort.Sort(byCube(sorted))
+			o.Anchor[r] = sorted[0]
+		}
+
+		o.Shape[r] = r
+		for s := 0; s < r; s++ {
+			if sameVectors(normalizeVectors(o.Offsets[r]), normalizeVectors(o.Offsets[s])) {
+				o.Shape[r] = s
+				break
+			}
+		}
+
+		if o.Shape[r] == r {
+			o.Distinct++
+		}
+	}
+
+	return o
+}
+
+// OrientationTable precomputes the rotations of every unit template.
+func OrientationTable(units []Unit) []Orientations {
+	t := make([]Orientations, len(units))
+	for i := range units {
+		t[i] = NewOrientations(&units[i])
+	}
+	return t
+}
+
+// Canonical returns the smallest rotation covering the same cells as r.
+func (o *Orientations) Canonical(r int) int {
+	return ((r % o.Period) + o.Period) % o.Period
+}
+
+type byCube []CubeVector
+
+func (s byCube) Len() int      { return len(s) }
+func (s byCube) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byCube) Less(i, j int) bool {
+	if s[i].Y != s[j].Y {
+		return s[i].Y < s[j].Y
+	}
+	return s[i].X < s[j].X
+}
+
+// normalizeVectors returns a sorted copy of vs, translated so that the first
+// vector is zero. Sets which are translations of each other normalize to the
+// same thing.
+func normalizeVectors(vs []CubeVector) []CubeVector {
+	n := append([]CubeVector(nil), vs...)
+	sort.Sort(byCube(n))
+
+	if len(n) == 0 {
+		return n
+	}
+
+	min := n[0]
+	for i := range n {
+		n[i] = CubeVector{n[i].X - min.X, n[i].Y - min.Y, n[i].Z - min.Z}
+	}
+	return n
+}
+
+// sameVectors returns whether a and b contain the same vectors, in any order.
+func sameVectors(a, b []CubeVector) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sa := append([]CubeVector(nil), a...)
+	sb := append([]CubeVector(nil), b...)
+	sort.Sort(byCube(sa))
+	sort.Sort(byCube(sb))
+
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"testing"
+)
+
+func sameCells(a, b []Cell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, c := range a {
+		if !c.EqualsAny(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRotateSixTimes(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		for i := range p.Units {
+			orig := p.Units[i].DeepCopy()
+
+			// Move the unit onto the board, as when it spawns.
+			NewBoard(p.Width, p.Height, nil).CenterUnit(orig)
+
+			for _, ccw := range []bool{false, true} {
+				u := orig
+				for r := 0; r < 6; r++ {
+					u = u.Rotate(ccw)
+				}
+
+				if !sameCells(u.Members, orig.Members) || u.Pivot != orig.Pivot {
+					t.Errorf("problem %d unit %d rotated 6 times (ccw %v) got %+v want %+v", p.Id, i, ccw, u, orig)
+				}
+			}
+
+			if u := orig.Rotate(false).Rotate(true); !sameCells(u.Members, orig.Members) {
+				t.Errorf("problem %d unit %d CW then CCW got %+v want %+v", p.Id, i, u, orig)
+			}
+		}
+	}
+}
+
+func TestOrientationOffsets(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		for i := range p.Units {
+			u := p.Units[i].DeepCopy()
+			NewBoard(p.Width, p.Height, nil).CenterUnit(u)
+			o := NewOrientations(u)
+
+			for r := 0; r < 6; r++ {
+				var cells []Cell
+				pc := u.Pivot.ToCube()
+				for _, v := range o.Offsets[r] {
+					cells = append(cells, pc.Add(v).ToCell())
+				}
+
+				if !sameCells(cells, u.Members) {
+					t.Errorf("problem %d unit %d rotation %d got %v want %v", p.Id, i, r, cells, u.Members)
+				}
+				u = u.Rotate(false)
+			}
+		}
+	}
+}
+
+func TestOrientationSymmetry(t *testing.T) {
+	cases := []struct {
+		name     string
+		u        Unit
+		period   int
+		distinct int
+	}{
+		{
+			name:     "single",
+			u:        Unit{Members: []Cell{{2, 2}}, Pivot: Cell{2, 2}},
+			period:   1,
+			distinct: 1,
+		},
+		{
+			name:     "single off pivot",
+			u:        Unit{Members: []Cell{{3, 2}}, Pivot: Cell{2, 2}},
+			period:   6,
+			distinct: 1,
+		},
+		{
+			name:     "line about center",
+			u:        Unit{Members: []Cell{{1, 2}, {2, 2}, {3, 2}}, Pivot: Cell{2, 2}},
+			period:   3,
+			distinct: 3,
+		},
+		{
+			name:     "line about end",
+			u:        Unit{Members: []Cell{{2, 2}, {3, 2}}, Pivot: Cell{2, 2}},
+			period:   6,
+			distinct: 3,
+		},
+		{
+			name: "ring",
+			u: Unit{
+				Members: []Cell{{1, 2}, {3, 2}, {1, 1}, {2, 1}, {1, 3}, {2, 3}},
+				Pivot:   Cell{2, 2},
+			},
+			period:   1,
+			distinct: 1,
+		},
+		{
+			// Every other neighbor of the pivot.
+			name: "tripod",
+			u: Unit{
+				Members: []Cell{{3, 2}, {1, 1}, {1, 3}},
+				Pivot:   Cell{2, 2},
+			},
+			period:   2,
+			distinct: 2,
+		},
+	}
+
+	for _, c := range cases {
+		o := NewOrientations(&c.u)
+		if o.Period != c.period {
+			t.Errorf("%s: Period got %d want %d", c.name, o.Period, c.period)
+		}
+		if o.Distinct != c.distinct {
+			t.Errorf("%s: Distinct got %d want %d", c.name, o.Distinct, c.distinct)
+		}
+	}
+}
+
+func TestCubeArithmetic(t *testing.T) {
+	for x := 0; x < 6; x++ {
+		for y := 0; y < 6; y++ {
+			a := Cell{x, y}.ToCube()
+			if a.X+a.Y+a.Z != 0 {
+				t.Errorf("Cell{%d, %d}.ToCube() = %+v, off the x+y+z=0 plane", x, y, a)
+			}
+
+			for _, d := range []Direction{E, NE, NW, W, SW, SE} {
+				b := Cell{x, y}.Translate(d).ToCube()
+				v := b.VectorFrom(a)
+
+				if got := a.Add(v); got != b {
+					t.Errorf("%+v.Add(%+v) got %+v want %+v", a, v, got, b)
+				}
+
+				// Six turns of a vector are no turn at all.
+				r := v
+				for i := 0; i < 6; i++ {
+					r = r.Rotate(false)
+				}
+				if r != v {
+					t.Errorf("%+v rotated 6 times got %+v", v, r)
+				}
+
+				// Every neighbor is one step away, and stays so
+				// when rotated.
+				if rv := v.Rotate(true); rv.X+rv.Y+rv.Z != 0 {
+					t.Errorf("%+v.Rotate(true) = %+v, off the x+y+z=0 plane", v, rv)
+				}
+			}
+		}
+	}
+}
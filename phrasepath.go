@@ -0,0 +1,370 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+var (
+	// chantBeamWidth is the number of partial paths kept at each step of
+	// a phrase search.
+	chantBeamWidth = 200
+
+	// phrases matches phrases of power as commands are played. It is
+	// built by normalizePhrases.
+	phrases *phraseAutomaton
+)
+
+// phraseAutomaton is an Aho-Corasick automaton over the normalized phrases
+// of power, with one transition per Direction.
+type phraseAutomaton struct {
+	next [][NOP]int
+	// out lists the phrases which end at each state.
+	out    [][]int
+	lens   []int
+	maxLen int
+}
+
+func newPhraseAutomaton(cs []Commands) *phraseAutomaton {
+	a := &phraseAutomaton{
+		next: make([][NOP]int, 1),
+		out:  make([][]int, 1),
+		lens: make([]int, len(cs)),
+	}
+
+	// goto[s][d] is -1 where the trie has no edge.
+	for d := range a.next[0] {
+		a.next[0][d] = -1
+	}
+
+	for i, phrase := range cs {
+		s := 0
+		for _, c := range phrase {
+			d := commandToDirection[c]
+			if d == NOP {
+				continue
+			}
+
+			if a.next[s][d] < 0 {
+				var row [NOP]int
+				for j := range row {
+					row[j] = -1
+				}
+				a.next = append(a.next, row)
+				a.out = append(a.out, nil)
+				a.next[s][d] = len(a.next) - 1
+			}
+			s = a.next[s][d]
+			a.lens[i]++
+		}
+		a.out[s] = append(a.out[s], i)
+
+		if a.lens[i] > a.maxLen {
+			a.maxLen = a.lens[i]
+		}
+	}
+
+	// Breadth first, fill in failure transitions so every state has an
+	// edge for every direction.
+	fail := make([]int, len(a.next))
+	var queue []int
+	for d := range a.next[0] {
+		if s := a.next[0][d]; s < 0 {
+			a.next[0][d] = 0
+		} else {
+			queue = append(queue, s)
+		}
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		a.out[s] = append(a.out[s], a.out[fail[s]]...)
+
+		for d := range a.next[s] {
+			t := a.next[s][d]
+			if t < 0 {
+				a.next[s][d] = a.next[fail[s]][d]
+				continue
+			}
+			fail[t] = a.next[fail[s]][d]
+			queue = append(queue, t)
+		}
+	}
+
+	return a
+}
+
+// Step returns the state after moving in direction d from state s.
+func (a *phraseAutomaton) Step(s int, d Direction) int {
+	return a.next[s][d]
+}
+
+// State returns the state after playing cs from the start.
+func (a *phraseAutomaton) State(cs Commands) int {
+	// Only the tail can still be part of a phrase.
+	if len(cs) > a.maxLen {
+		cs = cs[len(cs)-a.maxLen:]
+	}
+
+	s := 0
+	for _, c := range cs {
+		if d := commandToDirection[c]; d != NOP {
+			s = a.Step(s, d)
+		}
+	}
+	return s
+}
+
+// phraseSet is a set of phrase indices, as a bit mask with a word for every
+// 64 phrases. It is never changed once made, so it can be shared.
+type phraseSet []uint64
+
+func (s phraseSet) has(i int) bool {
+	return s[i/64]&(uint64(1)<<uint(i%64)) != 0
+}
+
+// without returns a copy of s without i.
+func (s phraseSet) without(i int) phraseSet {
+	c := append(phraseSet(nil), s...)
+	c[i/64] &^= uint64(1) << uint(i%64)
+	return c
+}
+
+// unusedPhrases returns the set of phrases not yet played in the game,
+// which will earn a bonus the first time they are.
+func (g *Game) unusedPhrases() phraseSet {
+	unused := make(phraseSet, (len(normalizedPhrases)+63)/64)
+	for i, p := range normalizedPhrases {
+		if g.powerWordCount[p] == 0 {
+			unused[i/64] |= uint64(1) << uint(i%64)
+		}
+	}
+	return unused
+}
+
+// gain returns the power points earned by reaching state s, given the set
+// of phrases which still earn a bonus. It returns the set with any newly
+// played phrases removed.
+func (a *phraseAutomaton) gain(s int, unused phraseSet) (float64, phraseSet) {
+	gain := 0.0
+	for _, i := range a.out[s] {
+		gain += float64(2 * a.lens[i])
+
+		if unused.has(i) {
+			gain += 300
+			unused = unused.without(i)
+		}
+	}
+	return gain, unused
+}
+
+// PhraseGain returns the power points cs would earn if played now.
+func (g *Game) PhraseGain(cs Commands) float64 {
+	s := phrases.State(g.Commands)
+	unused := g.unusedPhrases()
+	total := 0.0
+	for _, c := range cs {
+		d := commandToDirection[c]
+		if d == NOP {
+			continue
+		}
+
+		var gain float64
+		s = phrases.Step(s, d)
+		gain, unused = phrases.gain(s, unused)
+		total += gain
+	}
+	return total
+}
+
+type chantNode struct {
+	pos    UnitPosition
+	key    PositionKey
+	state  int
+	unused phraseSet
+	score  float64
+	d      Direction
+	parent *chantNode
+
+	// keys has a bit set for the hash of each key on the path, so most
+	// new positions can be accepted without walking the path.
+	keys uint64
+}
+
+// keyBit returns the bit for k in chantNode.keys. With only 64 buckets,
+// different keys often share a bit, which is fine: a set bit only means
+// the path must be walked to be sure.
+func keyBit(k PositionKey) uint64 {
+	h := uint(k.Anchor.X*31+k.Anchor.Y*17+k.Shape*7) % 64
+	return uint64(1) << h
+}
+
+// covered returns whether k is covered anywhere on the path to n.
+func (n *chantNode) covered(k PositionKey) bool {
+	if n.keys&keyBit(k) == 0 {
+		return false
+	}
+
+	for ; n != nil; n = n.parent {
+		if n.key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// commands returns the path to n, followed by d.
+func (n *chantNode) commands(d Direction) Commands {
+	var rev []Direction
+	rev = append(rev, d)
+	for ; n.parent != nil; n = n.parent {
+		rev = append(rev, n.d)
+	}
+
+	cs := make(Commands, len(rev))
+	for i, d := range rev {
+		cs[len(rev)-1-i] = directionToCommands[d][0]
+	}
+	return cs
+}
+
+type chantKey struct {
+	pos   UnitPosition
+	state int
+}
+
+type byChantScore []*chantNode
+
+func (s byChantScore) Len() int           { return len(s) }
+func (s byChantScore) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byChantScore) Less(i, j int) bool { return s[i].score > s[j].score }
+
+// chant beam searches, keeping width partial paths, for a path for the
+// current unit which scores the most power points, over at most maxSteps
+// moves, never moving the pivot below maxRow. finish returns whether the
+// unit may lock at a position, and the extra value of doing so. chant
+// returns the best path, including the locking command, and its value.
+//
+// Only the best path reaching each (position, phrase state) pair is kept,
+// so this is not exhaustive, but it never revisits a position or locks
+// early.
+func (g *Game) chant(finish func(UnitPosition) (float64, bool), maxRow, maxSteps, width int) (Commands, float64, bool) {
+	o := g.Orientations()
+
+	root := &chantNode{
+		pos:    g.currUnit,
+		key:    o.Key(g.currUnit),
+		state:  phrases.State(g.Commands),
+		unused: g.unusedPhrases(),
+	}
+	root.keys = keyBit(root.key)
+
+	// Positions covered before the search started.
+	visited := len(g.previousMoves) > 0
+
+	best := make(map[chantKey]float64)
+	bestScore := math.Inf(-1)
+	var bestNode *chantNode
+	var bestDir Direction
+
+	beam := []*chantNode{root}
+	for step := 0; step <= maxSteps && len(beam) > 0; step++ {
+		var next []*chantNode
+		for _, n := range beam {
+			if v, ok := finish(n.pos); ok {
+				for _, d := range lockOrder {
+					if o.Fits(g.B, o.Move(n.pos, d)) {
+						continue
+					}
+
+					gain, _ := phrases.gain(phrases.Step(n.state, d), n.unused)
+					if s := n.score + gain + v; s > bestScore {
+						bestScore, bestNode, bestDir = s, n, d
+					}
+				}
+			}
+
+			if step == maxSteps {
+				continue
+			}
+
+			for _, d := range moveOrder {
+				m := o.Move(n.pos, d)
+				if m.Pivot.Y > maxRow || !o.Fits(g.B, m) {
+					continue
+				}
+
+				k := o.Key(m)
+				if n.covered(k) || (visited && g.Visited(m)) {
+					continue
+				}
+
+				c := &chantNode{
+					pos:    m,
+					key:    k,
+					state:  phrases.Step(n.state, d),
+					d:      d,
+					parent: n,
+					keys:   n.keys | keyBit(k),
+				}
+
+				var gain float64
+				gain, c.unused = phrases.gain(c.state, n.unused)
+				c.score = n.score + gain
+
+				ck := chantKey{m, c.state}
+				if prev, ok := best[ck]; ok && prev >= c.score {
+					continue
+				}
+				best[ck] = c.score
+
+				next = append(next, c)
+			}
+		}
+
+		sort.Stable(byChantScore(next))
+		if len(next) > width {
+			next = next[:width]
+		}
+		beam = next
+	}
+
+	if bestNode == nil {
+		return nil, 0, false
+	}
+
+	return bestNode.commands(bestDir), bestScore, true
+}
+
+// PhrasePath finds commands which lock the current unit in the same cells
+// as target, playing as many phrases of power as possible on the way. It
+// falls back to target's own path if that scores better.
+func (g *Game) PhrasePath(target Placement) Commands {
+	o := g.Orientations()
+	k := o.Key(target.Position)
+
+	// The pivot never moves up, so there is no point going below the
+	// lowest pivot which covers the target cells.
+	maxRow := target.Position.Pivot.Y
+	for r := 0; r < o.Period; r++ {
+		if o.Shape[r] != k.Shape {
+			continue
+		}
+		if row := k.Anchor.Y - o.Anchor[r].Y; row > maxRow {
+			maxRow = row
+		}
+	}
+
+	finish := func(p UnitPosition) (float64, bool) {
+		return 0, o.Key(p) == k
+	}
+
+	maxSteps := len(target.Commands) + 2*g.B.Width
+	cs, score, ok := g.chant(finish, maxRow, maxSteps, chantBeamWidth)
+	if !ok || score < g.PhraseGain(target.Commands) {
+		return target.Commands
+	}
+	return cs
+}
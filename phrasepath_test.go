@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPhraseAutomaton(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	for i, phrase := range normalizedCommands {
+		var cs Commands
+		cs = append(cs, phrase...)
+
+		// Matching must not depend on what came before.
+		cs = append(Commands{directionToCommands[SE][0]}, cs...)
+
+		s := phrases.State(cs)
+		found := false
+		for _, j := range phrases.out[s] {
+			if j == i {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("phrase %q not matched at its end", normalizedPhrases[i])
+		}
+	}
+
+	// "ei!" scores 2*3 plus 300 the first time.
+	g := &Game{powerWordCount: make(map[string]int)}
+	if got := g.PhraseGain(Commands("ei!")); got != 306 {
+		t.Errorf("PhraseGain(ei!) got %v want 306", got)
+	}
+	if got := g.PhraseGain(Commands("ei!ei!")); got != 312 {
+		t.Errorf("PhraseGain(ei!ei!) got %v want 312", got)
+	}
+}
+
+func TestManyPhrases(t *testing.T) {
+	// More phrases than fit in one word of the unused set.
+	powerPhrases = nil
+	for i := 0; i < 70; i++ {
+		p := ""
+		for j := i; len(p) < 4; j /= 4 {
+			p += string("pbal"[j%4])
+		}
+		powerPhrases = append(powerPhrases, p)
+	}
+	normalizePhrases()
+	defer func() {
+		powerPhrases = defaultPhrases
+		normalizePhrases()
+	}()
+
+	g := &Game{powerWordCount: make(map[string]int)}
+	g.powerWordCount[normalizedPhrases[0]]++
+	if got := g.PhraseGain(Commands(normalizedPhrases[64])); got != 308 {
+		t.Errorf("PhraseGain(%s) with %s used got %v want 308", normalizedPhrases[64], normalizedPhrases[0], got)
+	}
+	if got := g.PhraseGain(Commands(normalizedPhrases[0])); got != 8 {
+		t.Errorf("PhraseGain(%s) used got %v want 8", normalizedPhrases[0], got)
+	}
+}
+
+func TestPhrasePath(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	for _, p := range QualifierProblems() {
+		if p.Width*p.Height > 100 {
+			continue
+		}
+
+		g := GamesFromProblem(p)[0]
+		chanted := 0
+		for _, pl := range g.Placements() {
+			cs := g.PhrasePath(pl)
+
+			f := g.Fork()
+			for i, c := range cs {
+				locked, _, err := f.Update(c)
+				if err != nil {
+					t.Fatalf("problem %d: path %s to %+v err %v", p.Id, &cs, pl.Position, err)
+				}
+				if last := i == len(cs)-1; locked != last {
+					t.Fatalf("problem %d: path %s to %+v command %d locked %v", p.Id, &cs, pl.Position, i, locked)
+				}
+			}
+
+			// The lock must be in the target cells.
+			want := g.Fork()
+			want.Play(pl.Commands)
+			for x := range want.B.Cells {
+				for y := range want.B.Cells[x] {
+					if want.B.Cells[x][y].Filled != f.B.Cells[x][y].Filled {
+						t.Fatalf("problem %d: path %s locked somewhere other than %+v", p.Id, &cs, pl.Position)
+					}
+				}
+			}
+
+			if f.PowerScore() < want.PowerScore() {
+				t.Errorf("problem %d: path %s scores %d, fewer than %s with %d", p.Id, &cs, f.PowerScore(), &pl.Commands, want.PowerScore())
+			}
+			if f.PowerScore() > 0 {
+				chanted++
+			}
+		}
+
+		if chanted == 0 {
+			t.Errorf("problem %d: no placement chanted any phrases", p.Id)
+		}
+	}
+}
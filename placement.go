@@ -0,0 +1,143 @@
+package main
+
+// Placement is somewhere the current unit can lock, with a path which
+// locks it there.
+type Placement struct {
+	// Position is where the unit is when it locks.
+	Position UnitPosition
+	// Commands moves the unit from its current position. The last
+	// command locks it.
+	Commands Commands
+}
+
+var (
+	// moveOrder is the order moves are explored when enumerating
+	// placements.
+	moveOrder = []Direction{SW, SE, W, E, CW, CCW}
+
+	// lockOrder is the preferred order of moves to lock a unit in place.
+	lockOrder = []Direction{SW, SE, W, E, CW, CCW}
+)
+
+type bfsStep struct {
+	parent UnitPosition
+	d      Direction
+	root   bool
+}
+
+// Placements returns every distinct place the current unit can lock,
+// reachable without revisiting a position or locking early. Each comes with
+// a shortest path to it. Placements covering the same cells are only
+// returned once.
+//
+// The search keeps only the first path found to each position, and never
+// extends a path back onto itself. A place which can only be reached by
+// going through some position along another path, which avoids where the
+// first one went, is missed. That needs the unit to turn back on itself,
+// which is rare. Every path returned plays without revisiting.
+func (g *Game) Placements() []Placement {
+	o := g.Orientations()
+
+	steps := map[UnitPosition]bfsStep{
+		g.currUnit: bfsStep{root: true},
+	}
+	// All keys of positions reached, so the path only needs walking when
+	// a key might be on it.
+	seenKeys := map[PositionKey]bool{
+		o.Key(g.currUnit): true,
+	}
+
+	// onPath returns whether k was covered on the path to p.
+	onPath := func(p UnitPosition, k PositionKey) bool {
+		for {
+			if o.Key(p) == k {
+				return true
+			}
+			s := steps[p]
+			if s.root {
+				return false
+			}
+			p = s.parent
+		}
+	}
+
+	var placements []Placement
+	locks := make(map[PositionKey]bool)
+
+	queue := []UnitPosition{g.currUnit}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, d := range moveOrder {
+			next := o.Move(p, d)
+			if _, ok := steps[next]; ok {
+				continue
+			}
+
+			if !o.Fits(g.B, next) {
+				continue
+			}
+
+			k := o.Key(next)
+			if seenKeys[k] && onPath(p, k) {
+				continue
+			}
+			if g.Visited(next) {
+				continue
+			}
+
+			steps[next] = bfsStep{parent: p, d: d}
+			seenKeys[k] = true
+			queue = append(queue, next)
+		}
+
+		k := o.Key(p)
+		if locks[k] {
+			continue
+		}
+
+		for _, d := range lockOrder {
+			if o.Fits(g.B, o.Move(p, d)) {
+				continue
+			}
+
+			locks[k] = true
+			placements = append(placements, Placement{
+				Position: p,
+				Commands: g.pathTo(steps, p, d),
+			})
+			break
+		}
+	}
+
+	return placements
+}
+
+// pathTo returns the commands following steps to p, then moving in d.
+func (g *Game) pathTo(steps map[UnitPosition]bfsStep, p UnitPosition, d Direction) Commands {
+	var rev []Direction
+	rev = append(rev, d)
+	for s := steps[p]; !s.root; s = steps[p] {
+		rev = append(rev, s.d)
+		p = s.parent
+	}
+
+	cs := make(Commands, len(rev))
+	for i, d := range rev {
+		cs[len(rev)-1-i] = directionToCommands[d][0]
+	}
+	return cs
+}
+
+// Play plays cs, stopping early if the game ends or a command fails. It
+// returns whether the last command played locked the unit.
+func (g *Game) Play(cs Commands) (locked bool, done bool, err error) {
+	for _, c := range cs {
+		locked, done, err = g.Update(c)
+		if done || err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPlacements(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		if p.Width*p.Height > 400 {
+			continue
+		}
+
+		g := GamesFromProblem(p)[0]
+		checkPlacements(t, g, p.Id)
+	}
+}
+
+// checkPlacements checks that every placement from g is returned once, and
+// that its path plays, locking the unit there with the last command.
+func checkPlacements(t *testing.T, g *Game, id int) {
+	placements := g.Placements()
+	if len(placements) == 0 {
+		t.Errorf("problem %d: no placements", id)
+	}
+
+	seen := make(map[PositionKey]bool)
+	for _, pl := range placements {
+		k := g.Key(pl.Position)
+		if seen[k] {
+			t.Errorf("problem %d: placement %+v returned twice", id, pl.Position)
+		}
+		seen[k] = true
+
+		f := g.Fork()
+		for i, c := range pl.Commands {
+			locked, _, err := f.Update(c)
+			if err != nil {
+				t.Fatalf("problem %d: placement %+v path %s err %v", id, pl.Position, &pl.Commands, err)
+			}
+			if last := i == len(pl.Commands)-1; locked != last {
+				t.Fatalf("problem %d: placement %+v path %s command %d locked %v", id, pl.Position, &pl.Commands, i, locked)
+			}
+		}
+
+		if f.previousLinesCleared > 0 {
+			continue
+		}
+		for _, c := range g.Cells(pl.Position) {
+			if !f.B.IsFilled(c) {
+				t.Errorf("problem %d: placement %+v did not fill %+v", id, pl.Position, c)
+			}
+		}
+	}
+}
+
+func TestPlacementsMidGame(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		if p.Width*p.Height > 400 {
+			continue
+		}
+
+		// Some units in, with the current unit moved, so that paths must
+		// not go back to where it has been.
+		g := GamesFromProblem(p)[0]
+		for i := 0; i < 5; i++ {
+			ps := g.Placements()
+			if _, done, _ := g.Play(ps[len(ps)/2].Commands); done {
+				break
+			}
+			for _, d := range []Direction{SW, CW, SE} {
+				if !g.Fits(g.Orientations().Move(g.currUnit, d)) {
+					break
+				}
+				if _, _, err := g.Update(directionToCommands[d][0]); err != nil {
+					break
+				}
+			}
+
+			checkPlacements(t, g, p.Id)
+		}
+	}
+}
+
+func TestPlacementsAllShapes(t *testing.T) {
+	// A pair can lie in three different directions, and every one of
+	// them can be reached on an open board.
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        5,
+		Height:       6,
+		Filled:       []Cell{{0, 5}, {1, 5}, {2, 5}, {3, 5}, {4, 5}},
+		SourceLength: 1,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	rotations := make(map[int]bool)
+	for _, pl := range g.Placements() {
+		rotations[g.Orientation(pl.Position).Shape[pl.Position.Rotation]] = true
+	}
+
+	if len(rotations) != 3 {
+		t.Errorf("placements cover %d shapes, want 3", len(rotations))
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A plan is a whole game as a choice of placement for each unit. Each choice
+// is a rank among the placements from that point, best first by their
+// Value. Units past the end of the plan take the best, so an empty plan is
+// the greedy game.
+type planResult struct {
+	plan []int
+
+	// eval ranks the placements.
+	eval Evaluator
+
+	// placed are the placements played for each unit.
+	placed []Placement
+	// games are the games before each unit, for replaying from partway.
+	games []*Game
+	// final is the game after the last unit.
+	final *Game
+}
+
+// Score returns the Objective of the game the plan ends with. That game is
+// played along shortest paths, so it earns few of the phrase points which
+// Commands chants on the way. Chanting every unit is too slow for the
+// searches, which rank plans by this instead, so they undervalue plans which
+// leave more room to chant. PlanAI checks the plan found against the greedy
+// one with chanting, before playing it.
+func (r *planResult) Score() float64 {
+	return r.final.Objective()
+}
+
+type byValue []Expansion
+
+func (s byValue) Len() int           { return len(s) }
+func (s byValue) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byValue) Less(i, j int) bool { return s[i].Value > s[j].Value }
+
+// rankedExpansions returns the placements from g, best first by eval.
+// Placements which kill the game are left out, unless they all do.
+func rankedExpansions(g *Game, eval Evaluator) []Expansion {
+	es := g.Expand(eval)
+
+	var live []Expansion
+	for _, e := range es {
+		if !e.Game.Died() {
+			live = append(live, e)
+		}
+	}
+	if len(live) > 0 {
+		es = live
+	}
+
+	sort.Stable(byValue(es))
+	return es
+}
+
+// newPlan returns an empty plan for g, which must have a fresh unit,
+// ranking placements with eval.
+func newPlan(g *Game, eval Evaluator) *planResult {
+	return &planResult{eval: eval, final: g, games: []*Game{g}}
+}
+
+// replayPlan plays plan from unit from, using base for the units before
+// that, so base must have reached unit from. Ranks past the placements
+// available take the worst one.
+func replayPlan(base *planResult, from int, plan []int) *planResult {
+	r := &planResult{
+		plan:   append([]int(nil), plan...),
+		eval:   base.eval,
+		placed: append([]Placement(nil), base.placed[:from]...),
+		games:  append([]*Game(nil), base.games[:from+1]...),
+	}
+
+	g := base.games[from]
+	for k := from; ; k++ {
+		es := rankedExpansions(g, r.eval)
+		if len(es) == 0 {
+			break
+		}
+
+		rank := 0
+		if k < len(plan) {
+			rank = plan[k]
+		}
+		if rank >= len(es) {
+			rank = len(es) - 1
+		}
+
+		e := es[rank]
+		r.placed = append(r.placed, e.Placement)
+		g = e.Game
+		if e.Done {
+			break
+		}
+		r.games = append(r.games, g)
+	}
+
+	r.final = g
+	if len(r.plan) > len(r.placed) {
+		r.plan = r.plan[:len(r.placed)]
+	}
+	return r
+}
+
+// Commands returns commands which play the placements of r from g, chanting
+// phrases of power on the way.
+func (r *planResult) Commands(g *Game) Commands {
+	f := r.chant(g)
+	return append(Commands(nil), f.Commands[len(g.Commands):]...)
+}
+
+// chant returns the game after playing the placements of r from g, chanting
+// phrases of power on the way.
+func (r *planResult) chant(g *Game) *Game {
+	g = g.Fork()
+	for _, p := range r.placed {
+		k := g.Key(p.Position)
+		target := p
+		for _, pl := range g.Placements() {
+			if g.Key(pl.Position) == k {
+				target = pl
+				break
+			}
+		}
+
+		_, done, err := g.Play(g.PhrasePath(target))
+		if done || err != nil {
+			break
+		}
+	}
+	return g
+}
+
+// A chantedPlan is a plan played from a game with chanting, as it would be
+// submitted.
+type chantedPlan struct {
+	name string
+	r    *planResult
+	// game is the game after the plan.
+	game *Game
+}
+
+func chantPlan(name string, r *planResult, g *Game) chantedPlan {
+	return chantedPlan{name: name, r: r, game: r.chant(g)}
+}
+
+// explainPlan explains choosing the plan chosen over other.
+func explainPlan(chosen, other chantedPlan) *Explanation {
+	choice := func(p chantedPlan) Choice {
+		return Choice{
+			Move:  fmt.Sprintf("%s plan for %d units", p.name, len(p.r.placed)),
+			Score: p.game.Objective(),
+			Terms: scoreTerms(p.game),
+		}
+	}
+
+	e := &Explanation{Chosen: choice(chosen)}
+	c := choice(other)
+	e.RunnerUp = &c
+	return e
+}
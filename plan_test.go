@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func planProblem() *InputProblem {
+	return &InputProblem{
+		Units: []Unit{
+			Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}},
+			Unit{Members: []Cell{{0, 0}, {1, 0}, {2, 0}}, Pivot: Cell{1, 0}},
+		},
+		Width:        8,
+		Height:       12,
+		Filled:       []Cell{{0, 11}, {1, 11}, {4, 11}},
+		SourceLength: 8,
+		SourceSeeds:  []uint64{0},
+	}
+}
+
+func TestReplayPlan(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	greedy := replayPlan(newPlan(g, testWeighted), 0, nil)
+	if len(greedy.placed) != 8 || len(greedy.games) != 8 {
+		t.Errorf("greedy plan placed %d units with %d games, want 8", len(greedy.placed), len(greedy.games))
+	}
+	for _, r := range greedy.plan {
+		if r != 0 {
+			t.Errorf("greedy plan %v, want all 0", greedy.plan)
+		}
+	}
+
+	// Replaying from partway must match replaying from the start.
+	plan := []int{0, 0, 2, 1}
+	whole := replayPlan(newPlan(g, testWeighted), 0, plan)
+	part := replayPlan(greedy, 2, plan)
+	if whole.Score() != part.Score() || whole.final.B.hash != part.final.B.hash {
+		t.Errorf("replay from 2 scored %v, from 0 scored %v", part.Score(), whole.Score())
+	}
+
+	// The commands for the plan lock the same placements, with phrases.
+	f := g.Fork()
+	cs := whole.Commands(g)
+	if _, _, err := f.Play(cs); err != nil {
+		t.Fatalf("Play(%s) err %v", &cs, err)
+	}
+	if f.B.hash != whole.final.B.hash {
+		t.Errorf("plan commands left a different board")
+	}
+	if f.Score() < whole.Score() {
+		t.Errorf("plan commands scored %v, less than the plan %v", f.Score(), whole.Score())
+	}
+}
+
+func TestAnneal(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	best, greedy := anneal(g, testWeighted, 100*time.Millisecond)
+	if want := replayPlan(newPlan(g, testWeighted), 0, nil); greedy.Score() != want.Score() {
+		t.Errorf("anneal returned greedy plan scoring %v, want %v", greedy.Score(), want.Score())
+	}
+	if best.Score() < greedy.Score() {
+		t.Errorf("anneal scored %v, less than greedy %v", best.Score(), greedy.Score())
+	}
+}
+
+func TestPlanAIChants(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	// Of the plan returned and greedy, the one which earns more once
+	// chanted is played, and scores what it was explained as.
+	a := &PlanAI{
+		game: g,
+		eval: testWeighted,
+		solve: func(g *Game, eval Evaluator) (*planResult, *planResult) {
+			greedy := replayPlan(newPlan(g, eval), 0, nil)
+			return replayPlan(greedy, 0, []int{3, 3, 3, 3, 3, 3, 3, 3}), greedy
+		},
+	}
+
+	var e *Explanation
+	for {
+		done, err := a.Next()
+		if x := explain(a); x != nil {
+			e = x
+		}
+		if done || err != nil {
+			break
+		}
+	}
+
+	if e == nil || e.RunnerUp == nil {
+		t.Fatalf("explanation %v, want a choice and runner-up", e)
+	}
+	if e.Chosen.Score < e.RunnerUp.Score {
+		t.Errorf("chose %v over better %v", &e.Chosen, e.RunnerUp)
+	}
+	if got := a.game.Objective(); got != e.Chosen.Score {
+		t.Errorf("played plan scored %v, explained as %v", got, e.Chosen.Score)
+	}
+}
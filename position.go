@@ -0,0 +1,121 @@
+package main
+
+// UnitPosition is the position of a unit on the board: which template it
+// is, how many clockwise turns it has made (modulo its Period), and where
+// its pivot is. Member cells are derived from the template's Orientations,
+// so positions are cheap to copy, compare and hash.
+type UnitPosition struct {
+	Template int
+	Rotation int
+	Pivot    Cell
+}
+
+// PositionKey identifies the cells covered by a position. Two positions of
+// the same template have equal keys exactly when they cover the same cells,
+// even if their pivots differ.
+type PositionKey struct {
+	Template int
+	Shape    int
+	Anchor   CubeCell
+}
+
+// Move returns p moved in direction d.
+func (o *Orientations) Move(p UnitPosition, d Direction) UnitPosition {
+	switch d {
+	case CW:
+		p.Rotation = o.Canonical(p.Rotation + 1)
+	case CCW:
+		p.Rotation = o.Canonical(p.Rotation - 1)
+	default:
+		p.Pivot = p.Pivot.Translate(d)
+	}
+	return p
+}
+
+// Key returns the PositionKey of p.
+func (o *Orientations) Key(p UnitPosition) PositionKey {
+	return PositionKey{
+		Template: p.Template,
+		Shape:    o.Shape[p.Rotation],
+		Anchor:   p.Pivot.ToCube().Add(o.Anchor[p.Rotation]),
+	}
+}
+
+// AppendCells appends the cells covered by p to cells.
+func (o *Orientations) AppendCells(cells []Cell, p UnitPosition) []Cell {
+	pc := p.Pivot.ToCube()
+	for _, v := range o.Offsets[p.Rotation] {
+		cells = append(cells, pc.Add(v).ToCell())
+	}
+	return cells
+}
+
+// Fits returns whether every cell of p is on the board and empty.
+func (o *Orientations) Fits(b *Board, p UnitPosition) bool {
+	pc := p.Pivot.ToCube()
+	for _, v := range o.Offsets[p.Rotation] {
+		c := pc.Add(v).ToCell()
+		if !b.InBounds(c) || b.IsFilled(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Orientation returns the rotations of p's template.
+func (g *Game) Orientation(p UnitPosition) *Orientations {
+	return &g.orients[p.Template]
+}
+
+// Cells returns the cells covered by p.
+func (g *Game) Cells(p UnitPosition) []Cell {
+	return g.Orientation(p).AppendCells(nil, p)
+}
+
+// Unit returns p as a Unit, for display.
+func (g *Game) Unit(p UnitPosition) *Unit {
+	return &Unit{Members: g.Cells(p), Pivot: p.Pivot}
+}
+
+// Fits returns whether p can be placed on the current board.
+func (g *Game) Fits(p UnitPosition) bool {
+	return g.Orientation(p).Fits(g.B, p)
+}
+
+// Key returns the PositionKey of p.
+func (g *Game) Key(p UnitPosition) PositionKey {
+	return g.Orientation(p).Key(p)
+}
+
+// Move returns p moved in direction d.
+func (g *Game) Move(p UnitPosition, d Direction) UnitPosition {
+	return g.Orientation(p).Move(p, d)
+}
+
+// Visited returns whether the current unit has already covered the cells
+// of p.
+func (g *Game) Visited(p UnitPosition) bool {
+	k := g.Key(p)
+	if k == g.Key(g.currUnit) {
+		return true
+	}
+
+	for _, m := range g.previousMoves {
+		if k == m {
+			return true
+		}
+	}
+	return false
+}
+
+// spawnTable computes where each template spawns on a board of width w.
+func spawnTable(units []Unit, w int) []UnitPosition {
+	b := &Board{Width: w}
+	spawns := make([]UnitPosition, len(units))
+	for i := range units {
+		u := units[i].DeepCopy()
+		b.CenterUnit(u)
+		spawns[i] = UnitPosition{Template: i, Pivot: u.Pivot}
+	}
+	return spawns
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPositionMoves(t *testing.T) {
+	for _, p := range QualifierProblems() {
+		g := GamesFromProblem(p)[0]
+		for i := range p.Units {
+			u := p.Units[i].DeepCopy()
+			g.B.CenterUnit(u)
+			pos := g.spawns[i]
+
+			if !sameCells(g.Cells(pos), u.Members) {
+				t.Errorf("problem %d unit %d spawn cells got %v want %v", p.Id, i, g.Cells(pos), u.Members)
+			}
+
+			for _, d := range []Direction{E, W, SW, SE, CW, CCW} {
+				var want *Unit
+				switch d {
+				case CW, CCW:
+					want = u.Rotate(d == CCW)
+				default:
+					want = u.Translate(d)
+				}
+
+				moved := g.Move(pos, d)
+				if !sameCells(g.Cells(moved), want.Members) || moved.Pivot != want.Pivot {
+					t.Errorf("problem %d unit %d moved %s got %+v want %+v", p.Id, i, d, g.Unit(moved), want)
+				}
+			}
+		}
+	}
+}
+
+func TestPositionKey(t *testing.T) {
+	// A line pivoting about one end covers the same cells when turned
+	// half way round and moved back over itself.
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        6,
+		Height:       6,
+		SourceLength: 1,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	a := UnitPosition{Pivot: Cell{2, 2}}
+	b := UnitPosition{Rotation: 3, Pivot: Cell{3, 2}}
+	if !sameCells(g.Cells(a), g.Cells(b)) {
+		t.Fatalf("cells of %+v and %+v differ: %v, %v", a, b, g.Cells(a), g.Cells(b))
+	}
+	if g.Key(a) != g.Key(b) {
+		t.Errorf("Key(%+v) = %+v, Key(%+v) = %+v, want equal", a, g.Key(a), b, g.Key(b))
+	}
+
+	c := UnitPosition{Rotation: 3, Pivot: Cell{2, 2}}
+	if g.Key(a) == g.Key(c) {
+		t.Errorf("Key(%+v) == Key(%+v), want different", a, c)
+	}
+}
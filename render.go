@@ -55,7 +55,7 @@ func (hm *HexMask) ColorModel() color.Model {
 }
 
 func (hm *HexMask) origin() image.Point {
-	px := hm.cell.X*hm.hs.horiz + (hm.cell.Y%2)*hm.hs.horiz/2
+	px := hm.cell.X*hm.hs.horiz + (hm.cell.Y&1)*hm.hs.horiz/2
 	py := hm.cell.Y * hm.hs.vert
 	return image.Point{px, py}
 }
@@ -83,7 +83,7 @@ func drawHex(m draw.Image, board image.Rectangle, cell Cell, hs HexSize, i image
 
 func drawPivot(m draw.Image, board image.Rectangle, cell Cell, hs HexSize) {
 	h := (hs.size - hs.pivot) / 2
-	x := cell.X*hs.horiz + (cell.Y%2)*hs.horiz/2 + h
+	x := cell.X*hs.horiz + (cell.Y&1)*hs.horiz/2 + h
 	y := cell.Y*hs.vert + h
 	min := board.Min.Add(image.Point{x, y})
 	max := min.Add(image.Point{hs.pivot, hs.pivot})
@@ -94,6 +94,14 @@ type GameRenderer struct {
 	width, height, border int
 	frames                []*image.Paletted
 	hs                    HexSize
+
+	// Unit cells and pivot drawn on the previous frame, which must be
+	// repainted on the next one.
+	prevUnit  []Cell
+	prevPivot Cell
+
+	// watch finds the cells changed since the last frame.
+	watch boardWatcher
 }
 
 func NewGameRenderer(g *Game, border int, size int) *GameRenderer {
@@ -111,10 +119,10 @@ func NewGameRenderer(g *Game, border int, size int) *GameRenderer {
 	return &GameRenderer{width: g.B.Width, height: g.B.Height, border: border, hs: hs}
 }
 
-func gameFillColor(g *Game, x, y int) image.Image {
+func gameFillColor(g *Game, unit []Cell, x, y int) image.Image {
 	c := Cell{x, y}
 
-	if c.EqualsAny(g.currUnit.Members) {
+	if c.EqualsAny(unit) {
 		return &red
 	}
 
@@ -125,8 +133,10 @@ func gameFillColor(g *Game, x, y int) image.Image {
 	return &grey
 }
 
-// TODO(myenik) Render Unit
 // TODO(myenik) Merge this with RenderInputProblem?
+// AddFrame renders the current state of g. After the first frame, only the
+// cells changed on the board since the last frame, and the old and new unit,
+// are redrawn.
 func (r *GameRenderer) AddFrame(g *Game) {
 	height := r.height
 	width := r.width
@@ -136,17 +146,43 @@ func (r *GameRenderer) AddFrame(g *Game) {
 	board := image.Rect(border, border, hs.horiz/2+hs.horiz*width, hs.vert*height)
 	rect := image.Rectangle{image.ZP, board.Max.Add(image.Pt(border, border))}
 
-	// background
 	m := image.NewPaletted(rect, pal)
-	draw.Draw(m, rect, &white, image.ZP, draw.Src)
+	unit := g.Cells(g.currUnit)
+
+	if len(r.frames) == 0 {
+		// background
+		draw.Draw(m, rect, &white, image.ZP, draw.Src)
 
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			drawHex(m, board, Cell{x, y}, hs, gameFillColor(g, x, y))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				drawHex(m, board, Cell{x, y}, hs, gameFillColor(g, unit, x, y))
+			}
+		}
+		r.watch.Changes(g.B)
+	} else {
+		copy(m.Pix, r.frames[len(r.frames)-1].Pix)
+
+		redraw := append([]Cell(nil), r.prevUnit...)
+		redraw = append(redraw, r.prevPivot)
+		for _, bc := range r.watch.Changes(g.B) {
+			redraw = append(redraw, bc.Cell)
+		}
+		redraw = append(redraw, unit...)
+
+		for _, c := range redraw {
+			if !g.B.InBounds(c) {
+				// Pivots may hang off the board.
+				drawHex(m, board, c, hs, &white)
+				continue
+			}
+			drawHex(m, board, c, hs, gameFillColor(g, unit, c.X, c.Y))
 		}
 	}
+
 	drawPivot(m, board, g.currUnit.Pivot, hs)
 
+	r.prevUnit = unit
+	r.prevPivot = g.currUnit.Pivot
 	r.frames = append(r.frames, m)
 }
 
@@ -1,6 +1,8 @@
 package main
 
-import ()
+import (
+	"sync/atomic"
+)
 
 type Node struct {
 	id       int
@@ -11,6 +13,13 @@ type Node struct {
 	game     *Game
 	weights  map[string]float64
 	h        int
+
+	// lock is where the move locked a unit, as scored, or nil.
+	lock *UnitPosition
+
+	// length is the number of live nodes on the best path from here,
+	// including this one.
+	length int
 }
 
 var (
@@ -19,9 +28,14 @@ var (
 
 	depthWeight = 10.0
 
-	uniqueId int
+	uniqueId int64
 )
 
+// nextId returns a new node id. Searches may build trees in parallel.
+func nextId() int {
+	return int(atomic.AddInt64(&uniqueId, 1) - 1)
+}
+
 // TODO(mgyenik) make this and d Diection in Node a Command
 // and look for phrases of power.
 // This returns the best move to make from this node, it should
@@ -48,6 +62,17 @@ func (n *Node) BestMove() *Node {
 	return best
 }
 
+// bestIndex returns the index of BestMove in n.children.
+func (n *Node) bestIndex() int {
+	best := n.BestMove()
+	for i, c := range n.children {
+		if c == best {
+			return i
+		}
+	}
+	return 0
+}
+
 func (n *Node) IsLeaf() bool {
 	return n.children == nil
 }
@@ -56,19 +81,51 @@ func (n *Node) IsDead() bool {
 	return n.dead
 }
 
-func BuildScoreTree(d Direction, g *Game, depth int, height int) *Node {
+// prunedNode is a dead node standing in for a move which is not worth
+// searching.
+func prunedNode(d Direction) *Node {
+	n := &Node{
+		d:     d,
+		id:    nextId(),
+		score: -1000000000,
+		dead:  true,
+	}
+	return n
+}
+
+// buildChildren builds the subtrees for every move from g.
+func buildChildren(g *Game, depth int, height int, tt *TranspositionTable, limit *searchLimit, eval Evaluator) []*Node {
+	children := make([]*Node, nary)
+	for i := range children {
+		if g.redundantMove(dirs[i]) {
+			children[i] = prunedNode(dirs[i])
+			continue
+		}
+		children[i] = BuildScoreTree(dirs[i], g, depth, height, tt, limit, eval)
+	}
+	return children
+}
+
+// BuildScoreTree builds the tree of moves from g, starting with d, scoring
+// each game with eval. If tt is not nil, subtrees already in it are not
+// rebuilt. Once limit expires, the rest of the tree is pruned, so the result
+// is only good if limit has not expired.
+func BuildScoreTree(d Direction, g *Game, depth int, height int, tt *TranspositionTable, limit *searchLimit, eval Evaluator) *Node {
+	if limit.Expired() {
+		return prunedNode(d)
+	}
+
 	n := &Node{
 		d:       d,
-		id:      uniqueId,
+		id:      nextId(),
 		weights: make(map[string]float64),
 	}
-	uniqueId++
 
 	c := directionToCommands[d][0]
 
 	n.game = g.Fork()
 
-	unit := n.game.currUnit.DeepCopy()
+	unit := n.game.currUnit
 	locked, done, err := n.game.Update(c)
 	if err != nil {
 		// NO POINTS FOR U
@@ -84,36 +141,57 @@ func BuildScoreTree(d Direction, g *Game, depth int, height int) *Node {
 		return n
 	}
 
-	midY := 0.0
-	for _, c := range n.game.currUnit.Members {
-		midY += float64(c.Y)
+	// Leaves have never been judged on how they lock, only the moves
+	// above them.
+	var lock *UnitPosition
+	if locked && depth > 0 {
+		lock = &unit
 	}
-	midY /= float64(len(n.game.currUnit.Members))
 
-	n.weights["gameScore"] = n.game.Score()
-	n.weights["depth"] = depthWeight * (midY + float64(height))
+	n.lock = lock
+	n.weights["eval"] = eval.Evaluate(n.game, lock)
+	n.weights["depth"] = depthWeight * float64(height)
 
-	n.score = n.weights["gameScore"] + n.weights["depth"]
+	n.score = n.weights["eval"] + n.weights["depth"]
+	n.length = 1
 
 	if depth == 0 {
 		return n
 	}
 
-	n.children = make([]*Node, nary)
-	for i := range n.children {
-		n.children[i] = BuildScoreTree(dirs[i], n.game, depth-1, height+1)
+	// The best subtree score depends only on the game, except that every
+	// live node on its best path is worth depthWeight more for each level
+	// further from the root. Scores add up along the path, so subtrees
+	// searched deeper are not comparable, and only exact depths are reused.
+	var best float64
+	var h uint64
+	if tt != nil {
+		h = n.game.Hash()
 	}
-
-	if locked {
-		if n.game.B.GapBelowAny(unit) {
-			n.weights["locked"] = -10000
-		} else {
-			n.weights["locked"] = 10000
+	if e, ok := tt.Lookup(h); ok && e.Depth == depth {
+		best = e.Score + depthWeight*float64((height-e.Height)*e.Length)
+		n.length += e.Length
+		n.weights["transposed"] = 1
+	} else {
+		n.children = buildChildren(n.game, depth-1, height+1, tt, limit, eval)
+
+		bi := n.bestIndex()
+		best = n.children[bi].score
+		n.length += n.children[bi].length
+
+		if !limit.Expired() {
+			tt.Store(TTEntry{
+				Hash:   h,
+				Score:  best,
+				Depth:  depth,
+				Move:   bi,
+				Height: height,
+				Length: n.children[bi].length,
+			})
 		}
-		n.score += n.weights["locked"]
 	}
 
-	n.weights["bestMove"] = n.BestMove().score
+	n.weights["bestMove"] = best
 	n.score += n.weights["bestMove"]
 
 	return n
@@ -144,10 +222,7 @@ func (n *Node) GrowScoreTree() {
 	}
 
 	// We will grow non-dead leaf nodes by one.
-	n.children = make([]*Node, nary)
-	for i := range n.children {
-		n.children[i] = BuildScoreTree(dirs[i], n.game, 0, n.h+1)
-	}
+	n.children = buildChildren(n.game, 0, n.h+1, nil, nil, LegacyEvaluator{})
 
 	return
 }
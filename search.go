@@ -0,0 +1,175 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// treeDepth and chanterDepth are the depths searched by TreeAI and
+	// ChanterAI when there is no time budget.
+	treeDepth    = 5
+	chanterDepth = 4
+
+	// maxSearchDepth bounds iterative deepening within -movetime.
+	maxSearchDepth = 20
+
+	// deadline is when the whole run must be done, from -t, or zero if
+	// there is no limit.
+	deadline time.Time
+)
+
+// searchLimit is when a search must stop.
+type searchLimit struct {
+	deadline time.Time
+}
+
+// newMoveLimit returns the limit for searching one move, which is
+// -movetime from now, but never past the deadline. It returns nil if there
+// is no limit.
+func newMoveLimit() *searchLimit {
+	var d time.Time
+	if *moveTime > 0 {
+		d = time.Now().Add(*moveTime)
+	}
+	if !deadline.IsZero() && (d.IsZero() || deadline.Before(d)) {
+		d = deadline
+	}
+
+	if d.IsZero() {
+		return nil
+	}
+	return &searchLimit{deadline: d}
+}
+
+// Expired returns whether the search must stop. A nil limit never expires.
+func (l *searchLimit) Expired() bool {
+	return l != nil && time.Now().After(l.deadline)
+}
+
+// searchDepths returns the first and last depths to search, given the
+// depth to search with no time budget. With -movetime, searches deepen as
+// far as time allows. With only a deadline, they deepen to fixed, so that
+// there is always a shallower result if the deadline hits.
+func searchDepths(fixed int, limit *searchLimit) (int, int) {
+	switch {
+	case *moveTime > 0:
+		return 1, maxSearchDepth
+	case limit != nil:
+		return 1, fixed
+	}
+	return fixed, fixed
+}
+
+type byScore struct {
+	order  []int
+	scores []float64
+}
+
+func (s byScore) Len() int           { return len(s.order) }
+func (s byScore) Swap(i, j int)      { s.order[i], s.order[j] = s.order[j], s.order[i] }
+func (s byScore) Less(i, j int) bool { return s.scores[s.order[i]] > s.scores[s.order[j]] }
+
+// scoreOrder returns the indices of scores, best first.
+func scoreOrder(scores []float64) []int {
+	order := identityOrder(len(scores))
+	sort.Stable(byScore{order, scores})
+	return order
+}
+
+// identityOrder returns the indices of n moves in order.
+func identityOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// parallel calls f with each of order on -c workers, starting them in that
+// order, and waits for them all to finish.
+func parallel(order []int, f func(i int)) {
+	workers := *cpus
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for _, i := range order {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// deepen searches n root moves at increasing depths, until the limit
+// expires. search(i, depth, limit, tt) searches move i to depth with
+// transposition table tt, returning its score. After each iteration which is
+// worth using, keep is called with the moves it searched; the others must be
+// ignored.
+//
+// Each iteration searches the moves best first by the previous one, split
+// across -c workers. Each move gets its own fork of tt, merged back in move
+// order after the iteration, so the scores do not depend on which worker
+// stored what first. If the limit expires partway, the iteration is still
+// used as long as the previous best move was searched deeper. The first
+// iteration always finishes, so there is always a move to make.
+func deepen(n, fixed int, limit *searchLimit, tt *TranspositionTable, search func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64, keep func(searched []bool)) {
+	first, last := searchDepths(fixed, limit)
+
+	order := identityOrder(n)
+	for depth := first; depth <= last; depth++ {
+		l := limit
+		if depth == first {
+			l = nil
+		}
+
+		// Each move has its own slot, so the results do not depend on
+		// which worker searches what.
+		searched := make([]bool, n)
+		scores := make([]float64, n)
+		forks := make([]*TranspositionTable, n)
+		for i := range forks {
+			forks[i] = tt.Fork()
+		}
+
+		parallel(order, func(i int) {
+			if l.Expired() {
+				return
+			}
+			s := search(i, depth, l, forks[i])
+			if l.Expired() {
+				return
+			}
+			scores[i] = s
+			searched[i] = true
+		})
+
+		for _, f := range forks {
+			tt.Merge(f)
+		}
+
+		if !searched[order[0]] {
+			return
+		}
+		keep(searched)
+
+		for _, ok := range searched {
+			if !ok {
+				return
+			}
+		}
+		order = scoreOrder(scores)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeepenFixed(t *testing.T) {
+	var depths []int
+	search := func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64 {
+		depths = append(depths, depth)
+		return 0
+	}
+	kept := 0
+	keep := func(searched []bool) {
+		kept++
+	}
+
+	deepen(3, 4, nil, nil, search, keep)
+
+	if len(depths) != 3 || kept != 1 {
+		t.Fatalf("deepen searched depths %v kept %d, want depth 4 once", depths, kept)
+	}
+	for _, d := range depths {
+		if d != 4 {
+			t.Errorf("deepen searched depth %d, want 4", d)
+		}
+	}
+}
+
+func TestDeepenOrder(t *testing.T) {
+	defer func(d time.Duration) { *moveTime = d }(*moveTime)
+	*moveTime = time.Hour
+
+	// The last move is best, so it goes first after the first iteration.
+	var order []int
+	search := func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64 {
+		if depth == 2 {
+			order = append(order, i)
+		}
+		return float64(i)
+	}
+	keep := func(searched []bool) {}
+
+	limit := &searchLimit{deadline: time.Now().Add(time.Hour)}
+	defer func(d int) { maxSearchDepth = d }(maxSearchDepth)
+	maxSearchDepth = 2
+
+	deepen(3, 4, limit, nil, search, keep)
+
+	want := []int{2, 1, 0}
+	if len(order) != len(want) {
+		t.Fatalf("deepen searched %v at depth 2, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("deepen searched %v at depth 2, want %v", order, want)
+			break
+		}
+	}
+}
+
+func TestDeepenExpired(t *testing.T) {
+	defer func(d time.Duration) { *moveTime = d }(*moveTime)
+	*moveTime = time.Hour
+
+	// The limit has already expired, but the first iteration must still
+	// finish.
+	var depths []int
+	search := func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64 {
+		depths = append(depths, depth)
+		return 0
+	}
+	var kept [][]bool
+	keep := func(searched []bool) {
+		kept = append(kept, searched)
+	}
+
+	deepen(3, 4, &searchLimit{deadline: time.Now()}, nil, search, keep)
+
+	if len(depths) != 3 || depths[0] != 1 {
+		t.Errorf("deepen searched depths %v, want depth 1 only", depths)
+	}
+	if len(kept) != 1 {
+		t.Fatalf("deepen kept %d iterations, want 1", len(kept))
+	}
+	for i, ok := range kept[0] {
+		if !ok {
+			t.Errorf("move %d not searched", i)
+		}
+	}
+}
+
+func TestParallelSearch(t *testing.T) {
+	defer func(c int) { *cpus = c }(*cpus)
+
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        6,
+		Height:       8,
+		Filled:       []Cell{{0, 7}, {1, 7}, {4, 7}},
+		SourceLength: 4,
+		SourceSeeds:  []uint64{0},
+	}
+	g := GamesFromProblem(p)[0]
+
+	// Each search runs twice on the same tables, so the second reuses the
+	// first, as well as transpositions within itself.
+	scores := func(workers int) ([]float64, []float64) {
+		*cpus = workers
+
+		treeTable := NewTranspositionTable(1024)
+		chantTable := NewTranspositionTable(1024)
+		var tree, chant []float64
+		for run := 0; run < 2; run++ {
+			tree, chant = nil, nil
+			for _, c := range NewTreeDescender(g, treeTable, nil, LegacyEvaluator{}).root.children {
+				tree = append(tree, c.score)
+			}
+			for _, c := range NewChanterDescender(g, chantTable, nil, ScoreEvaluator{}).root.children {
+				chant = append(chant, c.score)
+			}
+		}
+
+		if hits, _ := treeTable.Stats(); hits == 0 {
+			t.Errorf("tree table never hit with %d workers", workers)
+		}
+		if hits, _ := chantTable.Stats(); hits == 0 {
+			t.Errorf("chant table never hit with %d workers", workers)
+		}
+		return tree, chant
+	}
+
+	tree1, chant1 := scores(1)
+	for run := 0; run < 5; run++ {
+		tree4, chant4 := scores(4)
+		for i := range tree1 {
+			if tree1[i] != tree4[i] {
+				t.Errorf("tree move %d scored %v with 1 worker, %v with 4", i, tree1[i], tree4[i])
+			}
+		}
+		for i := range chant1 {
+			if chant1[i] != chant4[i] {
+				t.Errorf("chant %d scored %v with 1 worker, %v with 4", i, chant1[i], chant4[i])
+			}
+		}
+	}
+}
@@ -9,9 +9,14 @@ import (
 
 type Frame struct {
 	BoardDelta []BoardCell
+	Position   UnitPosition
 	Unit       *Unit
 	Score      float64
 	AI         string
+
+	// Explanation is why the AI made the move leading to this frame, if
+	// it made a decision.
+	Explanation *Explanation
 }
 
 type GameSolveResponse struct {
@@ -25,26 +30,13 @@ type ReceivedProblem struct {
 	Repeater string
 }
 
-func getFrameDeltas(prev *Board, curr *Board) []BoardCell {
-	deltas := []BoardCell{}
-
-	if prev == nil {
-		log.Println("prev was nil")
-		return deltas
-	}
-
-	prev = prev.Fork()
-	curr = curr.Fork()
-
-	for y := range prev.Cells {
-		for x := range prev.Cells[y] {
-			if prev.Cells[x][y].Filled != curr.Cells[x][y].Filled {
-				log.Printf("delta at %s, %s", x, y)
-				deltas = append(deltas, curr.Cells[x][y])
-			}
-		}
+// getFrameDeltas returns the cells of b changed since the previous frame
+// watched by w, and starts a new frame.
+func getFrameDeltas(w *boardWatcher, b *Board) []BoardCell {
+	deltas := w.Changes(b)
+	if deltas == nil {
+		deltas = []BoardCell{}
 	}
-
 	return deltas
 }
 
@@ -76,25 +68,25 @@ func newGameHandler(w http.ResponseWriter, r *http.Request) {
 		Frames: []Frame{},
 	}
 
-	var prevBoard *Board
+	var watch boardWatcher
+	watch.Changes(g.B)
 
 	i := 1
 	for {
-		game := a.Game()
-
 		done, err := a.Next()
 
-		deltas := getFrameDeltas(prevBoard, game.B)
+		game := a.Game()
+		deltas := getFrameDeltas(&watch, game.B)
 
 		frame := Frame{
-			BoardDelta: deltas,
-			Unit:       game.currUnit.DeepCopy(),
-			Score:      game.Score(),
-			AI:         aiFlags[0],
+			BoardDelta:  deltas,
+			Position:    game.currUnit,
+			Unit:        game.Unit(game.currUnit),
+			Score:       game.Score(),
+			AI:          aiFlags[0],
+			Explanation: explain(a),
 		}
 
-		prevBoard = game.B.Fork()
-
 		response.Frames = append(response.Frames, frame)
 
 		if done {
@@ -110,7 +102,7 @@ func newGameHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
-	log.Printf("data ", problem.Repeater)
+	log.Printf("data %s", problem.Repeater)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, fmt.Sprintf("Unable to encode JSON: %v", err), http.StatusInternalServerError)
 		return
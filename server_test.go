@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,14 +13,25 @@ import (
 func TestNewGame(t *testing.T) {
 	record := httptest.NewRecorder()
 
-	d, err := os.Open("qualifiers/problem_0.json")
+	f, err := os.Open("qualifiers/problem_0.json")
 	if err != nil {
-		t.Fatalf("ioutil.ReadFile err: got %v want nil", err)
+		t.Fatalf("os.Open err: got %v want nil", err)
+	}
+
+	p, err := ParseInputProblem(f)
+	if err != nil {
+		t.Fatalf("ParseInputProblem err: got %v want nil", err)
+	}
+
+	var body bytes.Buffer
+	received := ReceivedProblem{Problem: *p, AI: "repeaterai", Repeater: "aaaaaa"}
+	if err := json.NewEncoder(&body).Encode(&received); err != nil {
+		t.Fatalf("Encode err: got %v want nil", err)
 	}
 
 	req := &http.Request{
 		Method: "POST",
-		Body:   d,
+		Body:   ioutil.NopCloser(&body),
 	}
 	newGameHandler(record, req)
 
@@ -26,11 +39,29 @@ func TestNewGame(t *testing.T) {
 		t.Errorf("record.Code got %d want 201", record.Code)
 	}
 
-	var resp newGameResponse
+	var resp GameSolveResponse
 	if err := json.NewDecoder(record.Body).Decode(&resp); err != nil {
-		t.Errorf("Decode(%s) err: got %v want nil", record.Body, err)
+		t.Fatalf("Decode(%s) err: got %v want nil", record.Body, err)
+	}
+
+	// Replaying the deltas over the initial board must give the final board.
+	b := resp.Board
+	for _, f := range resp.Frames {
+		for _, bc := range f.BoardDelta {
+			b.Cells[bc.X][bc.Y].Filled = bc.Filled
+		}
+	}
+
+	g := GamesFromProblem(p)[0]
+	for _, c := range received.Repeater {
+		g.Update(Command(c))
+	}
+
+	for x := range g.B.Cells {
+		for y := range g.B.Cells[x] {
+			if got, want := b.Cells[x][y].Filled, g.B.Cells[x][y].Filled; got != want {
+				t.Errorf("cell (%d, %d) filled got %v want %v", x, y, got, want)
+			}
+		}
 	}
-	// if resp.Token == "" {
-	// 	t.Errorf(`Token: got "", want something`)
-	// }
 }
@@ -5,12 +5,12 @@ import (
 )
 
 type SimpleAI struct {
-	index int
-	game  *Game
+	game    *Game
+	current Commands
 }
 
 func NewSimpleAI(g *Game, _ string) AI {
-	return &SimpleAI{index: 0, game: g}
+	return &SimpleAI{game: g}
 }
 
 // Game returns the Game used by the AI.
@@ -19,83 +19,61 @@ func (ai *SimpleAI) Game() *Game {
 	return ai.game
 }
 
-// func CheckPath(g *Game, int xOrigin, int yOrigin, int xTarget, int yTarget) bool {
-// 	board := g.B.Cells
-
-// }
-
-func moveDirection(ai *SimpleAI, d Direction) (bool, bool, error) {
-	fork := ai.game.Fork()
-	locked, done, err := fork.Update(directionToCommands[d][0])
-
-	if !locked {
-		ai.game = fork
-	}
-
-	return locked, done, err
-}
-
-// Next steps the AI one step, returning true if the game is
-// complete, or an error if the game cannot continue.
-func (ai *SimpleAI) Next() (bool, error) {
-	var coms Commands
-	for _, d := range []Direction{E, W, SE, SW, CCW, CW} {
-		coms = append(coms, directionToCommands[d][0])
-	}
-
-	leftMost := 0
-	bottomMost := 0
-
-	for y := range ai.game.B.Cells {
-		for x := range ai.game.B.Cells[y] {
-			cell := ai.game.B.Cells[x][y]
-			if cell.Filled == false {
-				if bottomMost < y {
-					bottomMost = y
-					leftMost = x
-					continue
-				}
+// target returns the column of the leftmost empty cell in the lowest row
+// with any empty cells.
+func (ai *SimpleAI) target() int {
+	b := ai.game.B
+	for y := b.Height - 1; y >= 0; y-- {
+		for x := 0; x < b.Width; x++ {
+			if !b.Cells[x][y].Filled {
+				return x
 			}
 		}
 	}
 
-	log.Printf("currUnit %v,", ai.game.currUnit)
-
-	firstMember := ai.game.currUnit.Members[0]
+	return 0
+}
 
-	// move left
-	if firstMember.X > leftMost {
-		locked, done, err := moveDirection(ai, W)
+// plan drops the unit into the column closest to the target that it can
+// reach, trying each rotation.
+func (ai *SimpleAI) plan() Commands {
+	u := ai.game.currUnit
+	column := ai.target() + u.Pivot.X - ai.game.Cells(u)[0].X
 
-		if !locked {
-			return done, err
+	for off := 0; off < ai.game.B.Width; off++ {
+		columns := []int{column - off, column + off}
+		if off == 0 {
+			columns = columns[:1]
 		}
-	}
-
-	// move right
-	if firstMember.X < leftMost {
-		locked, done, err := moveDirection(ai, E)
-
-		if !locked {
-			return done, err
+		for _, c := range columns {
+			for rot := 0; rot < ai.game.Orientations().Period; rot++ {
+				landing, cs, err := ai.game.StraightDrop(rot, c)
+				if err == nil {
+					log.Printf("Dropping to %+v with %s", landing, &cs)
+					return cs
+				}
+			}
 		}
 	}
 
-	// move southwest
-	locked, done, err := moveDirection(ai, SW)
+	// Nowhere good to go, just fall.
+	return Commands{directionToCommands[SE][0]}
+}
 
-	if !locked {
-		return done, err
+// Next steps the AI one step, returning true if the game is
+// complete, or an error if the game cannot continue.
+func (ai *SimpleAI) Next() (bool, error) {
+	if len(ai.current) == 0 {
+		ai.current = ai.plan()
 	}
 
-	// // move southeast
-	locked, done, err = moveDirection(ai, SE)
+	c := ai.current[0]
+	ai.current = ai.current[1:]
 
-	if !locked {
-		return done, err
+	locked, done, err := ai.game.Update(c)
+	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	if locked {
+		ai.current = nil
 	}
-
-	_, done, err = ai.game.Update(directionToCommands[SE][0])
-
 	return done, err
 }
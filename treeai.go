@@ -32,18 +32,33 @@ func (t *TreeDescender) Next() (Command, error) {
 	return directionToCommands[next.d][0], nil
 }
 
-func NewTreeDescender(g *Game) *TreeDescender {
-	// TODO(myenik) paramterize depth
-	depth := 5
+// NewTreeDescender searches the moves from g, scoring games with eval,
+// deepening until limit expires, or to treeDepth if there is no time budget.
+func NewTreeDescender(g *Game, tt *TranspositionTable, limit *searchLimit, eval Evaluator) *TreeDescender {
 	height := 0
 
 	// Fake root, there is no direction here.
 	root := &Node{}
 
-	root.children = make([]*Node, nary)
-	for i := range root.children {
-		root.children[i] = BuildScoreTree(dirs[i], g, depth-1, height+1)
+	children := make([]*Node, nary)
+	search := func(i, depth int, limit *searchLimit, tt *TranspositionTable) float64 {
+		if g.redundantMove(dirs[i]) {
+			children[i] = prunedNode(dirs[i])
+		} else {
+			children[i] = BuildScoreTree(dirs[i], g, depth-1, height+1, tt, limit, eval)
+		}
+		return children[i].score
+	}
+	keep := func(searched []bool) {
+		for i, ok := range searched {
+			if !ok {
+				children[i] = prunedNode(dirs[i])
+			}
+		}
+		root.children = children
+		children = make([]*Node, nary)
 	}
+	deepen(nary, treeDepth, limit, tt, search, keep)
 
 	root.score = root.BestMove().score
 
@@ -54,11 +69,19 @@ func NewTreeDescender(g *Game) *TreeDescender {
 type TreeAI struct {
 	game *Game
 	step int
+
+	// Remembered across steps, since each tree mostly overlaps the last.
+	tt *TranspositionTable
+
+	eval Evaluator
+	explained
 }
 
 func NewTreeAI(g *Game, _ string) AI {
 	return &TreeAI{
 		game: g,
+		tt:   NewTranspositionTable(ttSize),
+		eval: LegacyEvaluator{},
 	}
 }
 
@@ -66,11 +89,16 @@ func (a *TreeAI) Game() *Game {
 	return a.game
 }
 
+// SetEvaluator implements EvaluatorSetter.
+func (a *TreeAI) SetEvaluator(e Evaluator) {
+	a.eval = e
+}
+
 // Next steps the game, returning true when the game is done.
 func (a *TreeAI) Next() (bool, error) {
 	a.step++
 
-	t := NewTreeDescender(a.game)
+	t := NewTreeDescender(a.game, a.tt, newMoveLimit(), a.eval)
 
 	if *graph != "" {
 		name := fmt.Sprintf("%s.%d.dot", *graph, a.step)
@@ -81,6 +109,10 @@ func (a *TreeAI) Next() (bool, error) {
 		}
 	}
 
+	if !t.root.IsLeaf() {
+		a.last = explainNode(t.root, a.eval)
+	}
+
 	c, err := t.Next()
 	if err == errNoMoves {
 		// No possible moves, we are stuck!
@@ -94,26 +126,66 @@ func (a *TreeAI) Next() (bool, error) {
 
 	locked, done, err := a.game.Update(c)
 	log.Printf("Update(%s) -> locked %v done %v, %v", c, locked, done, err)
+	if done {
+		hits, misses := a.tt.Stats()
+		log.Printf("Transposition table hits %d misses %d", hits, misses)
+	}
 	return done, err
 }
 
+// explainNode explains choosing the best move from root over the next best
+// live one, as scored with eval.
+func explainNode(root *Node, eval Evaluator) *Explanation {
+	best := root.BestMove()
+	e := &Explanation{Chosen: nodeChoice(best, eval)}
+
+	var next *Node
+	for _, c := range root.children {
+		if c != best && !c.IsDead() && (next == nil || c.score > next.score) {
+			next = c
+		}
+	}
+	if next != nil {
+		c := nodeChoice(next, eval)
+		e.RunnerUp = &c
+	}
+	return e
+}
+
+// nodeChoice breaks down the score of n, splitting its evaluation into
+// eval's terms.
+func nodeChoice(n *Node, eval Evaluator) Choice {
+	c := Choice{Move: n.d.String(), Score: n.score, Terms: make(map[string]float64)}
+	if n.IsDead() {
+		return c
+	}
+
+	for k, v := range n.weights {
+		// Transposed marks a subtree from the table, it is not
+		// scored.
+		if k != "eval" && k != "transposed" {
+			c.Terms[k] = v
+		}
+	}
+	for k, v := range evalTerms(eval, n.game, n.lock) {
+		c.Terms["eval."+k] = v
+	}
+	return c
+}
+
 // TODO(myenik) XXX Lol dis is broke
 type RollingTreeDescender struct {
 	root *Node
 }
 
 func NewRollingTreeDescender(g *Game) *RollingTreeDescender {
-	// TODO(myenik) paramterize depth
-	depth := 5
+	depth := treeDepth
 	height := 0
 
 	// Fake root, there is no direction here.
 	root := &Node{}
 
-	root.children = make([]*Node, nary)
-	for i := range root.children {
-		root.children[i] = BuildScoreTree(dirs[i], g, depth-1, height+1)
-	}
+	root.children = buildChildren(g, depth-1, height+1, nil, nil, LegacyEvaluator{})
 
 	root.score = root.BestMove().score
 
@@ -141,6 +213,7 @@ func (t *RollingTreeDescender) Next() (Command, error) {
 type RollingTreeAI struct {
 	game *Game
 	r    *RollingTreeDescender
+	explained
 }
 
 func NewRollingTreeAI(g *Game, _ string) AI {
@@ -155,6 +228,10 @@ func (a *RollingTreeAI) Game() *Game {
 }
 
 func (a *RollingTreeAI) Next() (bool, error) {
+	if root := a.r.root; !root.IsLeaf() && !root.IsDead() {
+		a.last = explainNode(root, LegacyEvaluator{})
+	}
+
 	c, err := a.r.Next()
 	if err == errNoMoves {
 		return false, err
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+var (
+	// ttSize is the number of entries in each AI's transposition table.
+	ttSize = 1 << 16
+)
+
+// mix64 scrambles x (splitmix64's finalizer), for building hashes out of
+// small integers.
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// cellHash is the Zobrist key for a filled cell.
+func cellHash(c Cell) uint64 {
+	return mix64(uint64(uint32(c.X))<<32 | uint64(uint32(c.Y)))
+}
+
+func stringHash(s string) uint64 {
+	var h uint64 = 14695981039346656037
+	for i := 0; i < len(s); i++ {
+		h = (h ^ uint64(s[i])) * 1099511628211
+	}
+	return h
+}
+
+// Salts keep the different parts of a game hash apart.
+const (
+	saltPosition uint64 = iota + 1
+	saltUnits
+	saltLines
+	saltScore
+	saltPhrase
+	saltPhraseState
+	saltVisited
+)
+
+func positionHash(p UnitPosition) uint64 {
+	h := mix64(uint64(p.Template)<<8 | uint64(p.Rotation))
+	return mix64(h ^ cellHash(p.Pivot))
+}
+
+func keyHash(k PositionKey) uint64 {
+	h := mix64(uint64(k.Template)<<8 | uint64(k.Shape))
+	return mix64(h ^ cellHash(k.Anchor.ToCell()))
+}
+
+// Hash returns a hash of everything which affects how the game plays out
+// from here, and its score: the board, the current unit and where it has
+// been, the units left, the scores and the partly played phrase.
+func (g *Game) Hash() uint64 {
+	h := g.B.hash
+	h ^= mix64(saltPosition ^ positionHash(g.currUnit))
+	h ^= mix64(saltUnits<<32 ^ uint64(g.unitsSent))
+	h ^= mix64(saltLines<<32 ^ uint64(g.previousLinesCleared))
+	h ^= mix64(saltScore ^ math.Float64bits(g.moveScore))
+
+	for p, n := range g.powerWordCount {
+		h ^= mix64(saltPhrase ^ stringHash(p) ^ mix64(uint64(n)))
+	}
+	if phrases != nil {
+		h ^= mix64(saltPhraseState<<32 ^ uint64(phrases.State(g.Commands)))
+	}
+
+	// Positions can only be visited once, so their order does not matter.
+	for _, k := range g.previousMoves {
+		h ^= mix64(saltVisited ^ keyHash(k))
+	}
+
+	return h
+}
+
+// TTEntry is a search result remembered by a TranspositionTable.
+type TTEntry struct {
+	Hash uint64
+	// Score of the position, as defined by the search.
+	Score float64
+	// Depth searched below the position.
+	Depth int
+	// Move is the index of the best move found.
+	Move int
+
+	// Height and Length are for searches whose scores depend on how far
+	// from the root they are: the height the position was searched at,
+	// and the number of nodes which contributed to the score.
+	Height int
+	Length int
+
+	valid bool
+}
+
+// TranspositionTable remembers search results by game Hash, so positions
+// reached by different paths, or in consecutive searches, are only searched
+// once. When two positions collide, the one searched deeper is kept. It is
+// safe for concurrent use.
+type TranspositionTable struct {
+	mu      sync.Mutex
+	entries []TTEntry
+	mask    uint64
+
+	// A Fork keeps what is stored in it in local, and looks up the rest
+	// in parent.
+	parent *TranspositionTable
+	local  map[uint64]TTEntry
+
+	hits, misses int
+}
+
+// NewTranspositionTable makes a table holding size entries, rounded up to a
+// power of two.
+func NewTranspositionTable(size int) *TranspositionTable {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+
+	return &TranspositionTable{
+		entries: make([]TTEntry, n),
+		mask:    uint64(n - 1),
+	}
+}
+
+// Lookup returns the entry for hash h, if there is one. A nil table never
+// has entries.
+func (t *TranspositionTable) Lookup(h uint64) (TTEntry, bool) {
+	if t == nil {
+		return TTEntry{}, false
+	}
+
+	if t.parent != nil {
+		t.mu.Lock()
+		e, ok := t.local[h]
+		if ok {
+			t.hits++
+		}
+		t.mu.Unlock()
+		if ok {
+			return e, true
+		}
+		return t.parent.Lookup(h)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.entries[h&t.mask]
+	if !e.valid || e.Hash != h {
+		t.misses++
+		return TTEntry{}, false
+	}
+
+	t.hits++
+	return e, true
+}
+
+// Store saves e, unless its slot holds an entry searched deeper. Storing
+// into a nil table does nothing.
+func (t *TranspositionTable) Store(e TTEntry) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.parent != nil {
+		if old, ok := t.local[e.Hash]; ok && old.Depth > e.Depth {
+			return
+		}
+		e.valid = true
+		t.local[e.Hash] = e
+		return
+	}
+
+	slot := &t.entries[e.Hash&t.mask]
+	if slot.valid && slot.Depth > e.Depth {
+		return
+	}
+
+	e.valid = true
+	*slot = e
+}
+
+// Stats returns the number of lookups which hit and missed.
+func (t *TranspositionTable) Stats() (hits, misses int) {
+	if t == nil {
+		return 0, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hits, t.misses
+}
+
+// Fork returns a table which looks up entries in t, but keeps what is
+// stored in it to itself until it is merged back with Merge. Searches in
+// parallel each use their own fork, so what they find does not depend on
+// which stored first. t must not change while it has forks. The fork of a
+// nil table is nil.
+func (t *TranspositionTable) Fork() *TranspositionTable {
+	if t == nil {
+		return nil
+	}
+	return &TranspositionTable{parent: t, local: make(map[uint64]TTEntry)}
+}
+
+type byHash []TTEntry
+
+func (s byHash) Len() int           { return len(s) }
+func (s byHash) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byHash) Less(i, j int) bool { return s[i].Hash < s[j].Hash }
+
+// Merge stores the entries of fork f of t in t, in order of hash, so
+// merging the same forks in the same order always leaves the same table.
+func (t *TranspositionTable) Merge(f *TranspositionTable) {
+	if t == nil || f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	entries := make([]TTEntry, 0, len(f.local))
+	for _, e := range f.local {
+		entries = append(entries, e)
+	}
+	hits := f.hits
+	f.mu.Unlock()
+
+	sort.Sort(byHash(entries))
+	for _, e := range entries {
+		t.Store(e)
+	}
+
+	t.mu.Lock()
+	t.hits += hits
+	t.mu.Unlock()
+}
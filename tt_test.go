@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGameHash(t *testing.T) {
+	g := GamesFromProblem(QualifierProblems()[6])[0]
+
+	// The same moves in a different order reach the same game, except
+	// for where the unit has been.
+	a := g.Fork()
+	a.Play(Commands("ba"))
+	b := g.Fork()
+	b.Play(Commands("ab"))
+	if a.currUnit != b.currUnit {
+		t.Fatalf("units differ: %+v, %+v", a.currUnit, b.currUnit)
+	}
+	if a.Hash() == b.Hash() {
+		t.Errorf("Hash() equal after visiting different positions")
+	}
+
+	// Forks hash the same, until they diverge.
+	f := g.Fork()
+	if f.Hash() != g.Hash() {
+		t.Errorf("Fork().Hash() got %x want %x", f.Hash(), g.Hash())
+	}
+	f.Update('a')
+	if f.Hash() == g.Hash() {
+		t.Errorf("Hash() unchanged after a move")
+	}
+
+	// The board hash tracks the filled cells, however they got there.
+	b1 := NewBoard(5, 5, []Cell{{1, 1}, {2, 2}})
+	b2 := NewBoard(5, 5, []Cell{{2, 2}, {3, 3}})
+	b2.MarkUnfilled(Cell{3, 3})
+	b2.MarkFilled(Cell{1, 1})
+	if b1.hash != b2.hash {
+		t.Errorf("board hashes differ for the same cells")
+	}
+}
+
+func TestTranspositionTable(t *testing.T) {
+	tt := NewTranspositionTable(3)
+	if len(tt.entries) != 4 {
+		t.Errorf("NewTranspositionTable(3) has %d entries want 4", len(tt.entries))
+	}
+
+	if _, ok := tt.Lookup(5); ok {
+		t.Errorf("Lookup in empty table found something")
+	}
+
+	tt.Store(TTEntry{Hash: 5, Score: 1, Depth: 3})
+	if e, ok := tt.Lookup(5); !ok || e.Score != 1 {
+		t.Errorf("Lookup(5) got %+v, %v want score 1", e, ok)
+	}
+
+	// 9 collides with 5. Shallower results do not replace deeper ones.
+	tt.Store(TTEntry{Hash: 9, Score: 2, Depth: 2})
+	if _, ok := tt.Lookup(9); ok {
+		t.Errorf("shallower entry replaced a deeper one")
+	}
+
+	tt.Store(TTEntry{Hash: 9, Score: 2, Depth: 4})
+	if e, ok := tt.Lookup(9); !ok || e.Score != 2 {
+		t.Errorf("Lookup(9) got %+v, %v want score 2", e, ok)
+	}
+	if _, ok := tt.Lookup(5); ok {
+		t.Errorf("replaced entry still found")
+	}
+
+	var nilTable *TranspositionTable
+	nilTable.Store(TTEntry{Hash: 1})
+	if _, ok := nilTable.Lookup(1); ok {
+		t.Errorf("nil table found something")
+	}
+}
+
+func TestTranspositionTableFork(t *testing.T) {
+	tt := NewTranspositionTable(4)
+	tt.Store(TTEntry{Hash: 5, Score: 1, Depth: 3})
+
+	f := tt.Fork()
+	if e, ok := f.Lookup(5); !ok || e.Score != 1 {
+		t.Errorf("fork Lookup(5) got %+v, %v want the parent's entry", e, ok)
+	}
+
+	// Stores stay in the fork until merged.
+	f.Store(TTEntry{Hash: 6, Score: 2, Depth: 1})
+	if _, ok := tt.Lookup(6); ok {
+		t.Errorf("fork stored into its parent")
+	}
+	if e, ok := f.Lookup(6); !ok || e.Score != 2 {
+		t.Errorf("fork Lookup(6) got %+v, %v want score 2", e, ok)
+	}
+
+	tt.Merge(f)
+	if e, ok := tt.Lookup(6); !ok || e.Score != 2 {
+		t.Errorf("merged Lookup(6) got %+v, %v want score 2", e, ok)
+	}
+
+	var nilTable *TranspositionTable
+	if nilTable.Fork() != nil {
+		t.Errorf("fork of a nil table is not nil")
+	}
+	nilTable.Merge(f)
+}
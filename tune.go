@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// tuneStartStep is how far each weight is first moved when tuning,
+	// as a fraction of its size. It halves after every round which finds
+	// nothing better, until it is below tuneMinStep.
+	tuneStartStep = 0.5
+	tuneMinStep   = 0.02
+)
+
+// loadWeights reads weights written by saveWeights. Features missing from
+// the file keep their default weight.
+func loadWeights(name string) (Weights, error) {
+	w := defaultWeights
+
+	f, err := os.Open(name)
+	if err != nil {
+		return w, err
+	}
+	defer f.Close()
+
+	var m map[string]float64
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return w, fmt.Errorf("could not parse weights in %s: %v", name, err)
+	}
+
+	for k, v := range m {
+		found := false
+		for i, n := range featureNames {
+			if n == k {
+				w[i], found = v, true
+			}
+		}
+		if !found {
+			return w, fmt.Errorf("unknown feature %q in %s", k, name)
+		}
+	}
+	return w, nil
+}
+
+// saveWeights writes w as a JSON object keyed by feature name.
+func saveWeights(name string, w Weights) error {
+	m := make(map[string]float64)
+	for i, n := range featureNames {
+		m[n] = w[i]
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, append(b, '\n'), 0644)
+}
+
+// playGame plays g to the end with the AI called ai, searching with eval,
+// and returns the final score.
+func playGame(g *Game, ai string, eval Evaluator) float64 {
+	a := ais[ai](g, "")
+	if s, ok := a.(EvaluatorSetter); ok {
+		s.SetEvaluator(eval)
+	}
+
+	for {
+		done, err := a.Next()
+		if done || err != nil {
+			break
+		}
+	}
+
+	a.Game().WriteFinalCommands()
+	return a.Game().FinalScore()
+}
+
+// judgeWeights returns the mean final score of ai over games when it
+// searches with w, playing the games on -c workers.
+func judgeWeights(games []*Game, ai string, w Weights) float64 {
+	scores := make([]float64, len(games))
+	parallel(identityOrder(len(games)), func(i int) {
+		scores[i] = playGame(games[i].Fork(), ai, &WeightedEvaluator{Weights: w})
+	})
+
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	return total / float64(len(games))
+}
+
+// coordinateSearch improves start for at most rounds rounds, trying each
+// weight a step up and down, and keeping any change which judge scores
+// higher. The score weight is left alone, as it sets the scale for the
+// rest. It returns the best weights found and their score.
+func coordinateSearch(start Weights, rounds int, judge func(Weights) float64) (Weights, float64) {
+	best := start
+	bestScore := judge(best)
+
+	step := tuneStartStep
+	for r := 0; r < rounds && step >= tuneMinStep; r++ {
+		improved := false
+		for i := range best {
+			if i == featScore {
+				continue
+			}
+
+			delta := step * math.Abs(best[i])
+			if delta == 0 {
+				delta = step
+			}
+
+			for _, sign := range []float64{1, -1} {
+				w := best
+				w[i] += sign * delta
+				if s := judge(w); s > bestScore {
+					best, bestScore = w, s
+					improved = true
+					break
+				}
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Round %d, step %v: %v scores %f\n", r, step, best, bestScore)
+		if !improved {
+			step /= 2
+		}
+	}
+
+	return best, bestScore
+}
+
+// loadGames returns the games from the problems in names, or every
+// qualifier problem if there are none, with at most seeds games from each
+// problem if seeds is more than 0.
+func loadGames(names []string, seeds int) ([]*Game, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = filepath.Glob("qualifiers/*.json"); err != nil {
+			return nil, err
+		}
+	}
+
+	var games []*Game
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := ParseInputProblem(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JSON in input file %s: %v", name, err)
+		}
+
+		gs := GamesFromProblem(p)
+		if seeds > 0 && len(gs) > seeds {
+			gs = gs[:seeds]
+		}
+		games = append(games, gs...)
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no games in %v", names)
+	}
+	return games, nil
+}
+
+// runTune tunes the weights of the weighted evaluator by playing -tune_ai
+// on the games from -f, or every qualifier problem, and saves the best
+// weights found to -weights.
+func runTune() error {
+	if *weightsFile == "" {
+		return fmt.Errorf("no -weights file to save to")
+	}
+	if _, ok := ais[*tuneAI]; !ok {
+		return fmt.Errorf("invalid AI %q", *tuneAI)
+	}
+
+	games, err := loadGames(inputFiles, *tuneSeeds)
+	if err != nil {
+		return err
+	}
+
+	judge := func(w Weights) float64 {
+		return judgeWeights(games, *tuneAI, w)
+	}
+	best, score := coordinateSearch(defaultWeights, *tuneRounds, judge)
+
+	fmt.Fprintf(os.Stderr, "Best weights %v score %f over %d games\n", best, score, len(games))
+	return saveWeights(*weightsFile, best)
+}
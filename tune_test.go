@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeightsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "weights")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "w.json")
+	w := Weights{featScore: 1, featHoles: -3.5, featBlocked: -100}
+	if err := saveWeights(name, w); err != nil {
+		t.Fatalf("saveWeights err %v", err)
+	}
+	if got, err := loadWeights(name); err != nil || got != w {
+		t.Errorf("loadWeights got %v, %v want %v", got, err, w)
+	}
+
+	// Missing features keep their defaults.
+	ioutil.WriteFile(name, []byte(`{"holes": -1}`), 0644)
+	want := defaultWeights
+	want[featHoles] = -1
+	if got, err := loadWeights(name); err != nil || got != want {
+		t.Errorf("loadWeights got %v, %v want %v", got, err, want)
+	}
+
+	ioutil.WriteFile(name, []byte(`{"nonsense": -1}`), 0644)
+	if _, err := loadWeights(name); err == nil {
+		t.Errorf("loadWeights of unknown feature got no error")
+	}
+}
+
+func TestCoordinateSearch(t *testing.T) {
+	// Best at holes -10, height -1, whatever the rest.
+	judge := func(w Weights) float64 {
+		dh, dy := w[featHoles]+10, w[featHeight]+1
+		return -dh*dh - dy*dy
+	}
+
+	start := Weights{featScore: 1, featHoles: -1, featHeight: -1}
+	got, score := coordinateSearch(start, 100, judge)
+	if got[featScore] != 1 {
+		t.Errorf("score weight changed to %v", got[featScore])
+	}
+	if score < -0.1 || score != judge(got) {
+		t.Errorf("got %v scoring %v, want near holes -10 height -1", got, score)
+	}
+}
+
+func TestJudgeWeights(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	games := GamesFromProblem(planProblem())
+	want := playGame(games[0].Fork(), "beamai", testWeighted)
+	if got := judgeWeights(games, "beamai", defaultWeights); got != want {
+		t.Errorf("judgeWeights got %v want %v", got, want)
+	}
+}
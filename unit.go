@@ -30,29 +30,6 @@ func (u *Unit) Occupied() []Cell {
 	return u.Members
 }
 
-// TODO(myenik) This is O(stupid), there has to be a better way...
-func (u *Unit) Overlaps(other *Unit) bool {
-	// If there is any cell in the input unit that is not present in the
-	// other unit, they do not overlap. Otherwise, they do overlap.
-	for _, c := range u.Members {
-		if !c.EqualsAny(other.Members) {
-			return false
-		}
-	}
-
-	return true
-}
-
-func CopyUnits(units []*Unit) []*Unit {
-	copies := make([]*Unit, len(units))
-
-	for i, u := range units {
-		copies[i] = u.DeepCopy()
-	}
-
-	return copies
-}
-
 // Deep copy copies the Unit and its cells.
 func (u *Unit) DeepCopy() *Unit {
 	r := &Unit{
@@ -67,16 +44,6 @@ func (u *Unit) DeepCopy() *Unit {
 	return r
 }
 
-func (u *Unit) OverlapsAny(others []*Unit) bool {
-	for _, other := range others {
-		if u.Overlaps(other) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // Left and rightmost Cells.
 func (u *Unit) Bounds() (Cell, Cell) {
 	leftmost := Cell{math.MaxInt32, 0}
@@ -0,0 +1,134 @@
+package main
+
+// lookaheadGood is how many good placements a unit needs before a board is
+// no longer short of room for it.
+var lookaheadGood = 3
+
+// Upcoming returns the templates of the next n units to place, starting
+// with the current one, and stopping at the end of the game. The order is
+// fixed by the seed, so peeking is free.
+func (g *Game) Upcoming(n int) []int {
+	if g.Died() || g.unitsLeft() <= 0 || n <= 0 {
+		return nil
+	}
+
+	ts := []int{g.currUnit.Template}
+	lcg := g.lcg
+	for sent := g.unitsSent; len(ts) < n && sent < g.numUnits; sent++ {
+		ts = append(ts, int(lcg.Next())%len(g.units))
+	}
+	return ts
+}
+
+// goodLock returns whether locking cells would clear a line, or would
+// leave no empty cells under them.
+func (b *Board) goodLock(cells []Cell) bool {
+	member := func(c Cell) bool {
+		for _, m := range cells {
+			if m == c {
+				return true
+			}
+		}
+		return false
+	}
+
+	holes := false
+	for _, c := range cells {
+		full := true
+		for x := 0; x < b.Width && full; x++ {
+			r := Cell{x, c.Y}
+			full = b.IsFilled(r) || member(r)
+		}
+		if full {
+			return true
+		}
+
+		for _, d := range []Direction{SW, SE} {
+			below := c.Translate(d)
+			if b.InBounds(below) && !b.IsFilled(below) && !member(below) {
+				holes = true
+			}
+		}
+	}
+	return !holes
+}
+
+// goodPlacements returns how many good places a unit of template t could
+// lock on the board, if it spawned now.
+func (g *Game) goodPlacements(t int) int {
+	spawn := g.spawns[t]
+	if !g.Fits(spawn) {
+		return 0
+	}
+
+	// A fresh unit on the same board, which is only read.
+	probe := &Game{B: g.B, orients: g.orients, currUnit: spawn}
+
+	good := 0
+	for _, p := range probe.Placements() {
+		if g.B.goodLock(probe.Cells(p.Position)) {
+			good++
+		}
+	}
+	return good
+}
+
+// clearLines returns how many lines locking cells would clear.
+func (b *Board) clearLines(cells []Cell) int {
+	rows := make(map[int]bool)
+	for _, c := range cells {
+		rows[c.Y] = true
+	}
+
+	lines := 0
+	for y := range rows {
+		full := true
+		for x := 0; x < b.Width && full; x++ {
+			r := Cell{x, y}
+			if b.IsFilled(r) {
+				continue
+			}
+			full = false
+			for _, c := range cells {
+				if c == r {
+					full = true
+				}
+			}
+		}
+		if full {
+			lines++
+		}
+	}
+	return lines
+}
+
+// maxClear returns the most lines a unit of template t could clear with one
+// lock, if it spawned now.
+func (g *Game) maxClear(t int) int {
+	spawn := g.spawns[t]
+	if !g.Fits(spawn) {
+		return 0
+	}
+
+	probe := &Game{B: g.B, orients: g.orients, currUnit: spawn}
+
+	most := 0
+	for _, p := range probe.Placements() {
+		if n := g.B.clearLines(probe.Cells(p.Position)); n > most {
+			most = n
+		}
+	}
+	return most
+}
+
+// starved returns how many good placements the next -lookahead units are
+// short of, in all.
+func (g *Game) starved() float64 {
+	short := 0
+	for _, t := range g.Upcoming(*lookahead) {
+		if good := g.goodPlacements(t); good < lookaheadGood {
+			short += lookaheadGood - good
+		}
+	}
+	return float64(short)
+}
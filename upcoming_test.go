@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUpcoming(t *testing.T) {
+	powerPhrases = defaultPhrases
+	normalizePhrases()
+
+	g := GamesFromProblem(planProblem())[0]
+
+	want := g.Upcoming(100)
+	if len(want) != g.numUnits {
+		t.Fatalf("Upcoming(100) got %d units, want all %d", len(want), g.numUnits)
+	}
+
+	for i := 0; ; i++ {
+		if got := g.Upcoming(100); len(got) != len(want)-i {
+			t.Fatalf("unit %d: Upcoming(100) got %v, want the rest of %v", i, got, want)
+		}
+		if g.currUnit.Template != want[i] {
+			t.Errorf("unit %d template %d, want %d", i, g.currUnit.Template, want[i])
+		}
+
+		ps := g.Placements()
+		_, done, err := g.Play(ps[len(ps)-1].Commands)
+		if err != nil {
+			t.Fatalf("unit %d: Play err %v", i, err)
+		}
+		if done {
+			break
+		}
+	}
+
+}
+
+func TestGoodLock(t *testing.T) {
+	// The bottom row is full except for 1, 2 and 5.
+	b := NewBoard(6, 4, []Cell{{0, 3}, {3, 3}, {4, 3}})
+
+	cases := []struct {
+		cells []Cell
+		want  bool
+	}{
+		// Fills the row, with nothing under it.
+		{[]Cell{{1, 3}, {2, 3}, {5, 3}}, true},
+		// Rests on the floor.
+		{[]Cell{{1, 3}, {2, 3}}, true},
+		// Bridges the gap at 1 and 2.
+		{[]Cell{{1, 2}, {2, 2}}, false},
+		// Rests on 3 and 4.
+		{[]Cell{{4, 2}}, true},
+	}
+	for _, c := range cases {
+		if got := b.goodLock(c.cells); got != c.want {
+			t.Errorf("goodLock(%v) got %v want %v", c.cells, got, c.want)
+		}
+	}
+}
+
+func TestStarved(t *testing.T) {
+	p := &InputProblem{
+		Units:        []Unit{Unit{Members: []Cell{{0, 0}, {1, 0}}, Pivot: Cell{0, 0}}},
+		Width:        4,
+		Height:       6,
+		SourceLength: 6,
+		SourceSeeds:  []uint64{0},
+	}
+	defer func(n int) { *lookahead = n }(*lookahead)
+	*lookahead = 2
+
+	g := GamesFromProblem(p)[0]
+	if got := g.starved(); got != 0 {
+		t.Errorf("empty board starved %v want 0", got)
+	}
+
+	// Nothing can spawn, so the coming units have no good placements.
+	for x := 0; x < 4; x++ {
+		g.B.MarkFilled(Cell{x, 0})
+	}
+	if got, want := g.starved(), float64(2*lookaheadGood); got != want {
+		t.Errorf("blocked board starved %v want %v", got, want)
+	}
+
+	*lookahead = 0
+	if got := g.starved(); got != 0 {
+		t.Errorf("starved %v with no lookahead", got)
+	}
+}